@@ -15,6 +15,7 @@ import (
 	"google.golang.org/api/option"
 
 	"github.com/FranMaggi73/dm-events-backend/internal/cache"
+	"github.com/FranMaggi73/dm-events-backend/internal/compendium"
 	"github.com/FranMaggi73/dm-events-backend/internal/handlers"
 	"github.com/FranMaggi73/dm-events-backend/internal/middleware"
 	"github.com/FranMaggi73/dm-events-backend/internal/ratelimit"
@@ -62,6 +63,17 @@ func main() {
 		}
 	}
 
+	// ===== COMPENDIO DE ITEMS =====
+	// COMPENDIUM_ITEMS_PATH permite ampliar o corregir el SRD incluido con un JSON propio
+	if path := os.Getenv("COMPENDIUM_ITEMS_PATH"); path != "" {
+		count, err := compendium.Default().ImportFile(path)
+		if err != nil {
+			log.Printf("⚠️  No se pudo importar el compendio %s: %v", path, err)
+		} else {
+			log.Printf("✅ Compendio ampliado con %d items", count)
+		}
+	}
+
 	// ===== RATE LIMITER CON REDIS =====
 	var rateLimiter middleware.RateLimiter
 
@@ -165,6 +177,12 @@ func main() {
 		protected.GET("/campaigns/:id", h.GetEvent)
 		protected.DELETE("/campaigns/:id", pm.RequireCampaignDM(), h.DeleteEvent)
 
+		// Progresión (XP / hitos)
+		protected.PUT("/campaigns/:id/progression", pm.RequireCampaignDM(), h.UpdateProgressionMode)
+		protected.POST("/campaigns/:id/xp", pm.RequireCampaignDM(), h.AwardXP)
+		protected.POST("/campaigns/:id/milestones", pm.RequireCampaignDM(), h.AwardMilestone)
+		protected.GET("/characters/:charId/xp-history", pm.RequireCharacterOwnerOrDM(), h.GetCharacterXPHistory)
+
 		// Miembros
 		protected.POST("/campaigns/:id/invite", pm.RequireCampaignDM(), middleware.RateLimitMiddleware(rateLimiter), h.InvitePlayer)
 		protected.DELETE("/campaigns/:id/players/:userId", pm.RequireCampaignDM(), h.RemovePlayer)
@@ -176,9 +194,28 @@ func main() {
 
 		// Personajes
 		protected.POST("/campaigns/:id/characters", pm.RequireCampaignMember(), middleware.RateLimitMiddleware(rateLimiter), h.CreateCharacter)
+		protected.POST("/campaigns/:id/characters/import", pm.RequireCampaignMember(), middleware.RateLimitMiddleware(rateLimiter), h.ImportCharacter)
 		protected.GET("/campaigns/:id/characters", h.GetCampaignCharacters)
 		protected.PUT("/characters/:charId", pm.RequireCharacterOwnerOrDM(), h.UpdateCharacter)
 		protected.DELETE("/characters/:charId", pm.RequireCharacterOwnerOrDM(), h.DeleteCharacter)
+		protected.GET("/characters/:charId/export", pm.RequireCharacterOwnerOrDM(), h.ExportCharacter)
+		protected.GET("/characters/:charId/history", pm.RequireCharacterOwnerOrDM(), h.GetCharacterHistory)
+		protected.POST("/characters/:charId/history/:versionId/restore", pm.RequireCharacterOwnerOrDM(), h.RestoreCharacterVersion)
+		protected.POST("/characters/:charId/rest", pm.RequireCharacterOwnerOrDM(), h.RestCharacter)
+		protected.POST("/characters/:charId/features/:featureId/use", pm.RequireCharacterOwnerOrDM(), h.UseFeature)
+		protected.POST("/characters/:charId/resources/:resourceId/spend", pm.RequireCharacterOwnerOrDM(), h.SpendResource)
+		protected.POST("/characters/:charId/resources/:resourceId/restore", pm.RequireCharacterOwnerOrDM(), h.RestoreResource)
+		protected.POST("/characters/:charId/hit-dice/spend", pm.RequireCharacterOwnerOrDM(), h.SpendHitDice)
+		protected.GET("/characters/:charId/stats", pm.RequireCharacterOwnerOrDM(), h.GetCharacterStats)
+		protected.GET("/characters/:charId/attacks", pm.RequireCharacterOwnerOrDM(), h.GetCharacterAttacks)
+		protected.POST("/characters/:charId/attacks/:itemId/roll", pm.RequireCharacterOwnerOrDM(), h.RollAttack)
+
+		// Baúl de personajes (independientes de las campañas)
+		protected.GET("/vault/characters", h.GetVaultCharacters)
+		protected.POST("/vault/characters", middleware.RateLimitMiddleware(rateLimiter), h.CreateVaultCharacter)
+		protected.POST("/characters/:charId/attach", pm.RequireCharacterOwner(), h.AttachCharacter)
+		protected.POST("/characters/:charId/detach", pm.RequireCharacterOwner(), h.DetachCharacter)
+		protected.POST("/characters/:charId/clone", pm.RequireCharacterOwner(), middleware.RateLimitMiddleware(rateLimiter), h.CloneCharacter)
 
 		// Encuentros
 		protected.POST("/campaigns/:id/encounters", pm.RequireCampaignDM(), middleware.RateLimitMiddleware(rateLimiter), h.CreateEncounter)
@@ -199,9 +236,12 @@ func main() {
 		// Notas
 		protected.POST("/campaigns/:id/notes", pm.RequireCampaignMember(), middleware.RateLimitMiddleware(rateLimiter), h.CreateNote)
 		protected.GET("/campaigns/:id/notes", pm.RequireCampaignMember(), h.GetCampaignNotes)
+		protected.GET("/campaigns/:id/notes/search", pm.RequireCampaignMember(), h.SearchCampaignNotes)
 		protected.GET("/notes/:noteId", h.GetNote)
+		protected.GET("/notes/:noteId/backlinks", h.GetNoteBacklinks)
 		protected.PUT("/notes/:noteId", h.UpdateNote)
 		protected.DELETE("/notes/:noteId", h.DeleteNote)
+		protected.POST("/notes/:noteId/reveal", middleware.RateLimitMiddleware(rateLimiter), h.RevealNote)
 
 		// Caché management
 		protected.POST("/cache/clear", h.ClearCache)
@@ -210,11 +250,67 @@ func main() {
 		// Inventory
 		protected.POST("/characters/:charId/items", pm.RequireCharacterOwnerOrDM(), middleware.RateLimitMiddleware(rateLimiter), h.CreateItem)
 		protected.GET("/characters/:charId/inventory", h.GetCharacterInventory)
-		protected.PUT("/items/:itemId", h.UpdateItem)
+		protected.POST("/characters/:charId/items/bulk", pm.RequireCharacterOwnerOrDM(), middleware.RateLimitMiddleware(rateLimiter), h.BulkItems)
+		protected.GET("/characters/:charId/items/export", pm.RequireCharacterOwnerOrDM(), h.ExportInventory)
+		protected.POST("/characters/:charId/items/import", pm.RequireCharacterOwnerOrDM(), middleware.RateLimitMiddleware(rateLimiter), h.ImportInventory)
+		protected.POST("/characters/:charId/items/starting-pack", pm.RequireCharacterOwnerOrDM(), middleware.RateLimitMiddleware(rateLimiter), h.ApplyStartingPack)
+		protected.GET("/starting-packs", h.GetStartingPacks)
+		protected.PUT("/items/:itemId", h.ReplaceItem)
+		protected.PATCH("/items/:itemId", h.UpdateItem)
 		protected.DELETE("/items/:itemId", h.DeleteItem)
+		protected.POST("/items/:itemId/transfer", middleware.RateLimitMiddleware(rateLimiter), h.TransferItem)
+		protected.POST("/items/:itemId/move", h.MoveItem)
+		protected.POST("/items/:itemId/use", h.UseItem)
+
+		// Compendio
+		protected.GET("/compendium/items", h.GetCompendiumItems)
+		protected.GET("/compendium/items/:slug", h.GetCompendiumItem)
+		protected.GET("/campaigns/:id/compendium/items", pm.RequireCampaignMember(), h.GetCampaignCompendiumItems)
+		protected.GET("/campaigns/:id/compendium/monsters", pm.RequireCampaignMember(), h.GetCampaignMonsters)
+		protected.GET("/campaigns/:id/compendium/spells", pm.RequireCampaignMember(), h.GetCampaignSpells)
+
+		// Homebrew
+		protected.GET("/homebrew", h.GetMyHomebrew)
+		protected.PUT("/homebrew/:homebrewId", h.UpdateHomebrew)
+		protected.DELETE("/homebrew/:homebrewId", h.DeleteHomebrew)
+		protected.POST("/campaigns/:id/homebrew", pm.RequireCampaignDM(), h.CreateHomebrew)
+		protected.GET("/campaigns/:id/homebrew/export", pm.RequireCampaignDM(), h.ExportHomebrewPack)
+		protected.POST("/campaigns/:id/homebrew/import", pm.RequireCampaignDM(), middleware.RateLimitMiddleware(rateLimiter), h.ImportHomebrewPack)
+
+		// Equipo y sintonía
+		protected.GET("/characters/:charId/equipment", pm.RequireCharacterOwnerOrDM(), h.GetCharacterEquipment)
+		protected.POST("/characters/:charId/items/:itemId/equip", pm.RequireCharacterOwnerOrDM(), h.EquipItem)
+		protected.POST("/characters/:charId/items/:itemId/unequip", pm.RequireCharacterOwnerOrDM(), h.UnequipItem)
+		protected.POST("/characters/:charId/items/:itemId/attune", pm.RequireCharacterOwnerOrDM(), h.AttuneItem)
+		protected.POST("/characters/:charId/items/:itemId/unattune", pm.RequireCharacterOwnerOrDM(), h.UnattuneItem)
+		protected.POST("/characters/:charId/items/:itemId/charges/spend", pm.RequireCharacterOwnerOrDM(), h.SpendItemCharges)
+		protected.POST("/characters/:charId/items/:itemId/charges/recharge", pm.RequireCharacterOwnerOrDM(), h.RechargeItem)
 
 		// Currency
 		protected.PUT("/characters/:charId/currency", h.UpdateCurrency)
+		protected.POST("/characters/:charId/currency/transactions", pm.RequireCharacterOwnerOrDM(), middleware.RateLimitMiddleware(rateLimiter), h.CreateCurrencyTransaction)
+		protected.GET("/characters/:charId/currency/ledger", pm.RequireCharacterOwnerOrDM(), h.GetCurrencyLedger)
+		protected.POST("/characters/:charId/currency/transfer", pm.RequireCharacterOwnerOrDM(), middleware.RateLimitMiddleware(rateLimiter), h.TransferCurrency)
+		protected.GET("/campaigns/:id/transfers", pm.RequireCampaignMember(), h.GetCampaignTransfers)
+
+		// Tiendas
+		protected.GET("/campaigns/:id/merchants", pm.RequireCampaignMember(), h.GetMerchants)
+		protected.POST("/campaigns/:id/merchants", pm.RequireCampaignDM(), h.CreateMerchant)
+		protected.GET("/campaigns/:id/merchants/:merchantId", pm.RequireCampaignMember(), h.GetMerchant)
+		protected.PUT("/campaigns/:id/merchants/:merchantId", pm.RequireCampaignDM(), h.UpdateMerchant)
+		protected.DELETE("/campaigns/:id/merchants/:merchantId", pm.RequireCampaignDM(), h.DeleteMerchant)
+		protected.POST("/campaigns/:id/merchants/:merchantId/stock", pm.RequireCampaignDM(), h.AddMerchantStock)
+		protected.DELETE("/campaigns/:id/merchants/:merchantId/stock/:stockId", pm.RequireCampaignDM(), h.RemoveMerchantStock)
+		protected.POST("/campaigns/:id/merchants/:merchantId/buy", pm.RequireCampaignMember(), middleware.RateLimitMiddleware(rateLimiter), h.BuyFromMerchant)
+		protected.POST("/campaigns/:id/merchants/:merchantId/sell", pm.RequireCampaignMember(), middleware.RateLimitMiddleware(rateLimiter), h.SellToMerchant)
+
+		// Fondo del grupo
+		protected.GET("/campaigns/:id/party", pm.RequireCampaignMember(), h.GetPartyStash)
+		protected.POST("/campaigns/:id/party/items", pm.RequireCampaignDM(), middleware.RateLimitMiddleware(rateLimiter), h.AddPartyItem)
+		protected.DELETE("/campaigns/:id/party/items/:itemId", pm.RequireCampaignDM(), h.DeletePartyItem)
+		protected.POST("/campaigns/:id/party/items/:itemId/claim", pm.RequireCampaignMember(), h.ClaimPartyItem)
+		protected.POST("/campaigns/:id/party/currency", pm.RequireCampaignDM(), h.AddPartyCurrency)
+		protected.POST("/campaigns/:id/party/split", pm.RequireCampaignDM(), h.SplitPartyCurrency)
 	}
 
 	// ===== CRON JOB =====
@@ -7,6 +7,7 @@ require (
 	firebase.google.com/go/v4 v4.18.0
 	github.com/gin-contrib/cors v1.7.6
 	github.com/gin-gonic/gin v1.11.0
+	golang.org/x/text v0.28.0
 	google.golang.org/api v0.247.0
 	google.golang.org/grpc v1.74.2
 )
@@ -83,7 +84,6 @@ require (
 	golang.org/x/oauth2 v0.30.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
-	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/time v0.12.0 // indirect
 	golang.org/x/tools v0.35.0 // indirect
 	google.golang.org/appengine/v2 v2.0.6 // indirect
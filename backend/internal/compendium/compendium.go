@@ -0,0 +1,253 @@
+// backend/internal/compendium/compendium.go
+package compendium
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// COMPENDIO DE ITEMS (SRD)
+// ===========================
+
+//go:embed data/items.json
+var embedded embed.FS
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Item - entrada del compendio (ver models.CompendiumItem)
+type Item = models.CompendiumItem
+
+// Rarity - rareza del item ("" si no es mágico)
+func Rarity(item Item) string {
+	if item.MagicData == nil {
+		return ""
+	}
+	return item.MagicData.Rarity
+}
+
+// ToInventoryItem - copia profunda del item lista para guardar en un inventario (sin IDs)
+func ToInventoryItem(item Item) models.InventoryItem {
+	var copied Item
+	data, _ := json.Marshal(item)
+	_ = json.Unmarshal(data, &copied)
+
+	return models.InventoryItem{
+		Name:          copied.Name,
+		Type:          copied.Type,
+		Description:   copied.Description,
+		Quantity:      1,
+		Value:         copied.Cost,
+		Weight:        copied.Weight,
+		Volume:        copied.Volume,
+		WeaponData:    copied.WeaponData,
+		ArmorData:     copied.ArmorData,
+		ContainerData: copied.ContainerData,
+		MagicData:     copied.MagicData,
+		Effect:        copied.Effect,
+		Open5eSlug:    copied.Slug,
+	}
+}
+
+// Slugify genera un slug a partir de un nombre ("Potion of Healing" -> "potion-of-healing")
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r == '\'' || r == ',':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// Filter - filtros de búsqueda; los vacíos no filtran
+type Filter struct {
+	Query    string
+	Type     string
+	Category string
+	Rarity   string
+	MinCost  *float64
+	MaxCost  *float64
+	Limit    int
+	Offset   int
+}
+
+// Page - resultado paginado de una búsqueda
+type Page struct {
+	Items []Item `json:"items"`
+	Total int    `json:"total"`
+}
+
+// Catalog - catálogo en memoria indexado por slug
+type Catalog struct {
+	mu     sync.RWMutex
+	items  []Item
+	bySlug map[string]int
+}
+
+var (
+	defaultCatalog *Catalog
+	defaultOnce    sync.Once
+)
+
+// Default - catálogo con los datos SRD incluidos en el binario
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		defaultCatalog = &Catalog{bySlug: make(map[string]int)}
+		file, err := embedded.Open("data/items.json")
+		if err != nil {
+			panic(fmt.Sprintf("compendio: %v", err))
+		}
+		defer file.Close()
+		if _, err := defaultCatalog.Import(file); err != nil {
+			panic(fmt.Sprintf("compendio: %v", err))
+		}
+	})
+	return defaultCatalog
+}
+
+// Import agrega (o reemplaza por slug) los items de un JSON con una lista de Item
+func (c *Catalog) Import(r io.Reader) (int, error) {
+	var entries []Item
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return 0, fmt.Errorf("JSON inválido: %w", err)
+	}
+
+	for i, entry := range entries {
+		entry.Slug = strings.ToLower(strings.TrimSpace(entry.Slug))
+		entry.Source = ""
+		if entry.Slug == "" || entry.Name == "" || entry.Type == "" {
+			return 0, fmt.Errorf("item %d: slug, name y type son obligatorios", i)
+		}
+		if entry.Cost < 0 || entry.Weight < 0 || entry.Volume < 0 {
+			return 0, fmt.Errorf("item %q: costo, peso y volumen no pueden ser negativos", entry.Slug)
+		}
+		entries[i] = entry
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range entries {
+		if index, ok := c.bySlug[entry.Slug]; ok {
+			c.items[index] = entry
+			continue
+		}
+		c.bySlug[entry.Slug] = len(c.items)
+		c.items = append(c.items, entry)
+	}
+	return len(entries), nil
+}
+
+// ImportFile importa un archivo JSON (p. ej. un compendio ampliado)
+func (c *Catalog) ImportFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return c.Import(file)
+}
+
+// Get busca un item por slug
+func (c *Catalog) Get(slug string) (Item, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	index, ok := c.bySlug[strings.ToLower(strings.TrimSpace(slug))]
+	if !ok {
+		return Item{}, false
+	}
+	return c.items[index], true
+}
+
+// Search filtra y pagina el catálogo
+func (c *Catalog) Search(f Filter) Page {
+	return Paginate(c.Filter(f), f)
+}
+
+// Filter devuelve todos los items del catálogo que cumplen el filtro (sin ordenar ni paginar)
+func (c *Catalog) Filter(f Filter) []Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	matches := []Item{}
+	for _, item := range c.items {
+		if Matches(item, f) {
+			item.Source = models.SourceSRD
+			matches = append(matches, item)
+		}
+	}
+	return matches
+}
+
+// Matches indica si el item cumple el filtro (sin límite ni offset)
+func Matches(item Item, f Filter) bool {
+	query := strings.ToLower(strings.TrimSpace(f.Query))
+	switch {
+	case f.Type != "" && !strings.EqualFold(string(item.Type), f.Type),
+		f.Category != "" && !strings.EqualFold(item.Category, f.Category),
+		f.Rarity != "" && !strings.EqualFold(Rarity(item), f.Rarity),
+		f.MinCost != nil && item.Cost < *f.MinCost,
+		f.MaxCost != nil && item.Cost > *f.MaxCost,
+		query != "" && !strings.Contains(strings.ToLower(item.Name), query):
+		return false
+	}
+	return true
+}
+
+// Paginate ordena los items (primero los que empiezan por la búsqueda, luego
+// alfabéticamente) y aplica límite y offset
+func Paginate(items []Item, f Filter) Page {
+	query := strings.ToLower(strings.TrimSpace(f.Query))
+	sort.SliceStable(items, func(a, b int) bool {
+		aName, bName := strings.ToLower(items[a].Name), strings.ToLower(items[b].Name)
+		if query != "" {
+			aStarts, bStarts := strings.HasPrefix(aName, query), strings.HasPrefix(bName, query)
+			if aStarts != bStarts {
+				return aStarts
+			}
+		}
+		return aName < bName
+	})
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	offset := f.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	page := Page{Items: []Item{}, Total: len(items)}
+	if offset < len(items) {
+		end := offset + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		page.Items = items[offset:end]
+	}
+	return page
+}
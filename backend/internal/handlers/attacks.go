@@ -0,0 +1,248 @@
+// backend/internal/handlers/attacks.go
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// ATAQUES CON ARMAS EQUIPADAS
+// ===========================
+
+// GetCharacterAttacks - Ataques del personaje a partir de sus armas equipadas
+func (h *Handler) GetCharacterAttacks(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	character, items, _, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildAttacks(character, items))
+}
+
+// RollAttack - Tirar un ataque con un arma equipada contra la CA de un combatiente
+// itemId "unarmed" usa el ataque desarmado.
+func (h *Handler) RollAttack(c *gin.Context) {
+	charID := c.Param("charId")
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	var req models.AttackRollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	character, items, _, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+
+	var attack *models.Attack
+	for _, a := range buildAttacks(character, items) {
+		if a.ItemID == itemID || (itemID == "unarmed" && a.ItemID == "") {
+			found := a
+			attack = &found
+			break
+		}
+	}
+	if attack == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Arma no equipada o inexistente"})
+		return
+	}
+
+	// El objetivo debe estar en un encuentro de la misma campaña
+	combatantDoc, err := h.db.Collection("combatants").Doc(req.TargetCombatantID).Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Combatiente no encontrado"})
+		return
+	}
+	var target models.Combatant
+	if err := combatantDoc.DataTo(&target); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando combatiente"})
+		return
+	}
+
+	encounterDoc, err := h.db.Collection("encounters").Doc(target.EncounterID).Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Encuentro no encontrado"})
+		return
+	}
+	var encounter models.Encounter
+	if err := encounterDoc.DataTo(&encounter); err != nil || encounter.CampaignID != character.CampaignID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El objetivo no está en un encuentro de la campaña del personaje"})
+		return
+	}
+
+	d20, natural := rollD20(req.Mode)
+	result := models.AttackRollResult{
+		Attack:     *attack,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		TargetAC:   target.ArmorClass,
+		D20:        d20,
+		Natural:    natural,
+		Total:      natural + attack.AttackBonus,
+		Critical:   natural == 20,
+	}
+	result.Hit = natural == 20 || (natural != 1 && result.Total >= target.ArmorClass)
+
+	if result.Hit {
+		damage := attack.Damage
+		if req.Versatile && attack.VersatileDamage != "" {
+			damage = attack.VersatileDamage
+		}
+		if expr, err := parseDice(damage); err == nil {
+			roll := expr.roll(result.Critical)
+			result.DamageRoll = &roll
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ===========================
+// CÁLCULO DE ATAQUES
+// ===========================
+
+// buildAttacks calcula los ataques de las armas equipadas más el ataque desarmado
+func buildAttacks(char *models.Character, items []models.InventoryItem) []models.Attack {
+	strMod := abilityModifier(char.AbilityScores.Strength)
+
+	attacks := []models.Attack{}
+	for _, item := range items {
+		if !item.Equipped || item.WeaponData == nil {
+			continue
+		}
+		attacks = append(attacks, weaponAttack(char, item))
+	}
+
+	// Ataque desarmado: 1 + FUE, siempre competente
+	attacks = append(attacks, models.Attack{
+		Name:        "Ataque desarmado",
+		Ability:     "strength",
+		Proficient:  true,
+		AttackBonus: strMod + char.ProficiencyBonus,
+		Damage:      diceExpr{modifier: maxInt(1+strMod, 1)}.String(),
+		DamageType:  "bludgeoning",
+		Reach:       5,
+		Properties:  []string{},
+	})
+
+	return attacks
+}
+
+// weaponAttack calcula bonificador de ataque y daño de un arma
+func weaponAttack(char *models.Character, item models.InventoryItem) models.Attack {
+	weapon := item.WeaponData
+	props := weapon.Properties
+	ranged := strings.Contains(strings.ToLower(weapon.WeaponType), "ranged")
+
+	strMod := abilityModifier(char.AbilityScores.Strength)
+	dexMod := abilityModifier(char.AbilityScores.Dexterity)
+
+	// A distancia usa DES; sutil usa la mejor entre FUE y DES
+	ability, mod := "strength", strMod
+	if ranged || (props.Finesse && dexMod > strMod) {
+		ability, mod = "dexterity", dexMod
+	}
+
+	proficient := isWeaponProficient(char, item)
+	bonus := mod + weapon.MagicBonus
+	if proficient {
+		bonus += char.ProficiencyBonus
+	}
+
+	attack := models.Attack{
+		ItemID:      item.ID,
+		Name:        item.Name,
+		Ability:     ability,
+		Proficient:  proficient,
+		AttackBonus: bonus,
+		DamageType:  weapon.DamageType,
+		Properties:  weaponPropertyNames(props),
+	}
+
+	damageBonus := mod + weapon.MagicBonus
+	if expr, err := parseDice(weapon.DamageDice); err == nil {
+		attack.Damage = expr.withModifier(damageBonus).String()
+	} else {
+		attack.Damage = diceExpr{modifier: maxInt(damageBonus, 1)}.String()
+	}
+
+	if props.Versatile != "" {
+		if expr, err := parseDice(props.Versatile); err == nil {
+			attack.VersatileDamage = expr.withModifier(damageBonus).String()
+		}
+	}
+
+	if !ranged {
+		attack.Reach = 5
+		if props.Reach {
+			attack.Reach = 10
+		}
+	}
+	if props.Range != nil && (ranged || props.Thrown) {
+		rng := *props.Range
+		attack.Range = &rng
+	}
+
+	return attack
+}
+
+// isWeaponProficient comprueba competencia por categoría ("simple"/"martial") o por nombre.
+// Los personajes sin competencias registradas se consideran competentes.
+func isWeaponProficient(char *models.Character, item models.InventoryItem) bool {
+	if len(char.WeaponProficiencies) == 0 {
+		return true
+	}
+
+	category := strings.ToLower(item.WeaponData.WeaponType)
+	name := strings.ToLower(strings.TrimSpace(item.Name))
+
+	for _, prof := range char.WeaponProficiencies {
+		p := strings.ToLower(strings.TrimSpace(prof))
+		switch {
+		case p == "simple" && strings.HasPrefix(category, "simple"),
+			p == "martial" && strings.HasPrefix(category, "martial"),
+			p == name, p == name+"s", p+"s" == name:
+			return true
+		}
+	}
+	return false
+}
+
+func weaponPropertyNames(props models.WeaponProperties) []string {
+	names := []string{}
+	flags := []struct {
+		enabled bool
+		name    string
+	}{
+		{props.Light, "light"}, {props.Finesse, "finesse"}, {props.Thrown, "thrown"},
+		{props.TwoHanded, "two-handed"}, {props.Versatile != "", "versatile"}, {props.Reach, "reach"},
+		{props.Loading, "loading"}, {props.Heavy, "heavy"}, {props.Ammunition, "ammunition"},
+	}
+	for _, f := range flags {
+		if f.enabled {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,431 @@
+// backend/internal/handlers/character_export.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+	"github.com/FranMaggi73/dm-events-backend/internal/pdf"
+)
+
+// ===========================
+// EXPORTACIÓN DE PERSONAJES (PDF / JSON)
+// ===========================
+
+const (
+	exportFormat  = "dm-events"
+	exportVersion = 1
+)
+
+// ExportCharacter - Exportar la hoja del personaje como PDF imprimible o bundle JSON re-importable
+func (h *Handler) ExportCharacter(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "pdf" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Formato inválido: usa pdf o json"})
+		return
+	}
+
+	character, items, currency, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+
+	derived := computeDerivedStats(character, items, currency)
+	filename := exportFilename(character.Name)
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, models.CharacterExport{
+			Format:     exportFormat,
+			Version:    exportVersion,
+			ExportedAt: time.Now(),
+			Character:  *character,
+			Derived:    derived,
+			Inventory:  items,
+			Currency:   currency,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+	c.Data(http.StatusOK, "application/pdf", renderCharacterSheet(character, derived, items, currency))
+}
+
+// loadCharacterSheet obtiene personaje, items y monedas
+func (h *Handler) loadCharacterSheet(ctx context.Context, charID string) (*models.Character, []models.InventoryItem, models.Currency, error) {
+	currency := models.Currency{}
+
+	charDoc, err := h.db.Collection("characters").Doc(charID).Get(ctx)
+	if err != nil {
+		return nil, nil, currency, err
+	}
+
+	var character models.Character
+	if err := charDoc.DataTo(&character); err != nil {
+		return nil, nil, currency, err
+	}
+
+	iter := h.db.Collection("inventory_items").
+		Where("characterId", "==", charID).
+		Documents(ctx)
+
+	items := []models.InventoryItem{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, currency, err
+		}
+
+		var item models.InventoryItem
+		if err := doc.DataTo(&item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	if currencyDoc, err := h.db.Collection("currencies").Doc(charID).Get(ctx); err == nil {
+		currencyDoc.DataTo(&currency)
+	}
+
+	return &character, items, currency, nil
+}
+
+var filenameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func exportFilename(name string) string {
+	clean := strings.Trim(filenameUnsafe.ReplaceAllString(name, "_"), "_")
+	if clean == "" {
+		return "personaje"
+	}
+	return clean
+}
+
+// parseNativeExport lee un bundle exportado por esta aplicación sin pérdida de datos
+func parseNativeExport(raw []byte) (*importedCharacter, error) {
+	var bundle models.CharacterExport
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		return nil, fmt.Errorf("bundle de exportación inválido: %v", err)
+	}
+	if bundle.Version > exportVersion {
+		return nil, fmt.Errorf("versión de exportación no soportada: %d", bundle.Version)
+	}
+
+	ic := &importedCharacter{
+		character: bundle.Character,
+		currency:  bundle.Currency,
+		report: models.ImportReport{
+			Format:   exportFormat,
+			Unmapped: []string{},
+			Warnings: []string{},
+		},
+	}
+
+	// Los IDs del bundle se conservan: reassignItemIDs los reemplaza al guardar y
+	// remapea ContainerID, así no se pierde el anidamiento de contenedores
+	for _, item := range bundle.Inventory {
+		item.CharacterID = ""
+		item.CampaignID = ""
+		ic.items = append(ic.items, item)
+	}
+
+	char := &ic.character
+	if char.Conditions == nil {
+		char.Conditions = []string{}
+	}
+	if char.Skills == nil {
+		char.Skills = []models.Skill{}
+	}
+	char.Languages = emptyIfNil(char.Languages)
+	char.ToolProficiencies = emptyIfNil(char.ToolProficiencies)
+	char.WeaponProficiencies = emptyIfNil(char.WeaponProficiencies)
+	char.ArmorProficiencies = emptyIfNil(char.ArmorProficiencies)
+	if char.Feats == nil {
+		char.Feats = []models.Feat{}
+	}
+	if char.Features == nil {
+		char.Features = []models.Feature{}
+	}
+	if char.Resources == nil {
+		char.Resources = []models.Resource{}
+	}
+	if char.HitDice == nil {
+		char.HitDice = []models.HitDicePool{}
+	}
+
+	clampImportedCharacter(ic)
+	if err := clampNativeBundle(ic); err != nil {
+		return nil, err
+	}
+	return ic, nil
+}
+
+// clampNativeBundle valida lo que solo traen los bundles nativos: monedas, recursos y
+// dados de golpe. Los recursos y dados inválidos se descartan con un aviso; los válidos
+// conservan su valor actual.
+func clampNativeBundle(ic *importedCharacter) error {
+	if err := validateCurrency(ic.currency); err != nil {
+		return fmt.Errorf("monedas inválidas: %v", err)
+	}
+
+	char := &ic.character
+	resources := []models.Resource{}
+	for _, resource := range char.Resources {
+		if len(resources) >= 30 {
+			ic.warn("solo se importaron 30 recursos")
+			break
+		}
+		if err := binding.Validator.ValidateStruct(resource); err != nil {
+			ic.warn("recurso %q descartado: %v", resource.Name, err)
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	char.Resources = prepareResources(resources, resources, char.Level, char.AbilityScores)
+
+	pools := []models.HitDicePool{}
+	for _, pool := range char.HitDice {
+		if len(pools) >= 4 {
+			ic.warn("solo se importaron 4 tipos de dados de golpe")
+			break
+		}
+		if err := binding.Validator.ValidateStruct(pool); err != nil {
+			ic.warn("dados de golpe d%d descartados: %v", pool.Die, err)
+			continue
+		}
+		pools = append(pools, pool)
+	}
+	char.HitDice = prepareHitDice(pools, pools, char.Class, char.Level)
+	return nil
+}
+
+// ===========================
+// RENDER PDF
+// ===========================
+
+const (
+	sheetMargin = 40.0
+	sheetWidth  = pdf.PageWidth - 2*sheetMargin
+)
+
+// sheetWriter escribe la hoja en una columna con salto de página automático
+type sheetWriter struct {
+	doc *pdf.Document
+	y   float64
+}
+
+func (w *sheetWriter) ensure(height float64) {
+	if w.y+height > pdf.PageHeight-sheetMargin {
+		w.doc.AddPage()
+		w.y = sheetMargin
+	}
+}
+
+func (w *sheetWriter) heading(text string) {
+	w.ensure(34)
+	w.y += 18
+	w.doc.Text(sheetMargin, w.y, 12, true, strings.ToUpper(text))
+	w.y += 4
+	w.doc.Line(sheetMargin, w.y, sheetMargin+sheetWidth, w.y)
+	w.y += 12
+}
+
+func (w *sheetWriter) paragraph(x float64, size float64, bold bool, text string) {
+	for _, line := range pdf.Wrap(text, size, sheetMargin+sheetWidth-x) {
+		w.ensure(size + 3)
+		w.doc.Text(x, w.y, size, bold, line)
+		w.y += size + 3
+	}
+}
+
+// statBox dibuja un recuadro con etiqueta y valor
+func (w *sheetWriter) statBox(x, width float64, label, value string) {
+	w.doc.Rect(x, w.y, width, 40)
+	w.doc.Text(x+6, w.y+12, 7, false, strings.ToUpper(label))
+	w.doc.Text(x+6, w.y+32, 14, true, value)
+}
+
+func signed(value int) string {
+	return fmt.Sprintf("%+d", value)
+}
+
+var abilityLabels = map[string]string{
+	"strength": "Fuerza", "dexterity": "Destreza", "constitution": "Constitución",
+	"intelligence": "Inteligencia", "wisdom": "Sabiduría", "charisma": "Carisma",
+}
+
+// renderCharacterSheet genera la hoja de personaje en PDF
+func renderCharacterSheet(char *models.Character, derived models.DerivedStats, items []models.InventoryItem, currency models.Currency) []byte {
+	w := &sheetWriter{doc: pdf.New(), y: sheetMargin}
+	w.doc.AddPage()
+
+	// ===== Cabecera =====
+	w.y += 20
+	w.doc.Text(sheetMargin, w.y, 22, true, char.Name)
+	w.y += 18
+
+	subtitle := []string{fmt.Sprintf("%s nivel %d", char.Class, char.Level)}
+	if race := strings.TrimSpace(char.Race + " " + char.Subrace); race != "" {
+		subtitle = append(subtitle, race)
+	}
+	if char.Background != nil {
+		subtitle = append(subtitle, char.Background.Name)
+	}
+	if char.Alignment != "" {
+		subtitle = append(subtitle, char.Alignment)
+	}
+	subtitle = append(subtitle, fmt.Sprintf("%d XP", char.Experience))
+	w.paragraph(sheetMargin, 10, false, strings.Join(subtitle, " • "))
+	w.y += 8
+
+	// ===== Recuadros de combate =====
+	boxes := []struct{ label, value string }{
+		{"Clase de armadura", fmt.Sprintf("%d", char.ArmorClass)},
+		{"Iniciativa", signed(char.Initiative)},
+		{"Velocidad", fmt.Sprintf("%d ft", derived.Speed)},
+		{"Puntos de golpe", fmt.Sprintf("%d / %d", char.CurrentHP, char.MaxHP)},
+		{"PG temporales", fmt.Sprintf("%d", char.TemporaryHP)},
+		{"Competencia", signed(char.ProficiencyBonus)},
+		{"Percepción pasiva", fmt.Sprintf("%d", derived.PassivePerception)},
+	}
+	boxWidth := (sheetWidth - float64(len(boxes)-1)*6) / float64(len(boxes))
+	for i, box := range boxes {
+		w.statBox(sheetMargin+float64(i)*(boxWidth+6), boxWidth, box.label, box.value)
+	}
+	w.y += 48
+
+	if len(char.Conditions) > 0 {
+		w.paragraph(sheetMargin, 9, false, "Condiciones: "+strings.Join(char.Conditions, ", "))
+	}
+
+	// ===== Características y salvaciones =====
+	w.heading("Características")
+	scores := map[string]int{}
+	for _, ability := range abilityOrder {
+		scores[ability] = abilityScore(char.AbilityScores, ability)
+	}
+	for _, ability := range abilityOrder {
+		w.ensure(14)
+		mark := "[ ]"
+		if hasSaveProficiency(char.SavingThrows, ability) {
+			mark = "[x]"
+		}
+		w.doc.Text(sheetMargin, w.y, 10, true, abilityLabels[ability])
+		w.doc.Text(sheetMargin+110, w.y, 10, false, fmt.Sprintf("%d (%s)", scores[ability], signed(derived.AbilityModifiers[ability])))
+		w.doc.Text(sheetMargin+220, w.y, 10, false, fmt.Sprintf("Salvación %s %s", signed(derived.SavingThrows[ability]), mark))
+		w.y += 14
+	}
+
+	// ===== Skills en dos columnas =====
+	if len(char.Skills) > 0 {
+		w.heading("Habilidades")
+		half := (len(char.Skills) + 1) / 2
+		w.ensure(float64(half) * 13)
+		top := w.y
+		for i, skill := range char.Skills {
+			x := sheetMargin
+			y := top + float64(i)*13
+			if i >= half {
+				x = sheetMargin + sheetWidth/2
+				y = top + float64(i-half)*13
+			}
+			mark := "[ ]"
+			if skill.Expertise {
+				mark = "[E]"
+			} else if skill.Proficient {
+				mark = "[x]"
+			}
+			w.doc.Text(x, y, 9, false, fmt.Sprintf("%s %s %s", mark, signed(derived.Skills[skill.Name]), skill.Name))
+		}
+		w.y = top + float64(half)*13
+	}
+
+	// ===== Competencias e idiomas =====
+	w.heading("Competencias e idiomas")
+	proficiencies := []struct {
+		label  string
+		values []string
+	}{
+		{"Armaduras", char.ArmorProficiencies},
+		{"Armas", char.WeaponProficiencies},
+		{"Herramientas", char.ToolProficiencies},
+		{"Idiomas", char.Languages},
+	}
+	for _, p := range proficiencies {
+		value := "—"
+		if len(p.values) > 0 {
+			value = strings.Join(p.values, ", ")
+		}
+		w.paragraph(sheetMargin, 9, false, p.label+": "+value)
+	}
+	if char.Background != nil && char.Background.Feature != "" {
+		w.paragraph(sheetMargin, 9, false, "Rasgo de trasfondo: "+char.Background.Feature)
+	}
+
+	// ===== Dotes y rasgos =====
+	if len(char.Feats) > 0 || len(char.Features) > 0 {
+		w.heading("Dotes y rasgos")
+		for _, feat := range char.Feats {
+			w.paragraph(sheetMargin, 10, true, feat.Name+" (dote)")
+			if feat.Description != "" {
+				w.paragraph(sheetMargin+10, 8, false, feat.Description)
+			}
+		}
+		for _, feature := range char.Features {
+			title := feature.Name
+			if feature.Uses != nil {
+				title += fmt.Sprintf(" [%d/%d, descanso %s]", feature.Uses.Current, feature.Uses.Max,
+					map[string]string{models.RechargeShortRest: "corto", models.RechargeLongRest: "largo"}[feature.Uses.Recharge])
+			}
+			w.paragraph(sheetMargin, 10, true, title)
+			if feature.Description != "" {
+				w.paragraph(sheetMargin+10, 8, false, feature.Description)
+			}
+		}
+	}
+
+	// ===== Inventario =====
+	w.heading("Inventario")
+	if len(items) == 0 {
+		w.paragraph(sheetMargin, 9, false, "Sin objetos")
+	}
+	for _, item := range items {
+		w.ensure(13)
+		detail := string(item.Type)
+		if item.WeaponData != nil && item.WeaponData.DamageDice != "" {
+			detail += fmt.Sprintf(" • %s %s", item.WeaponData.DamageDice, item.WeaponData.DamageType)
+		}
+		if item.ArmorData != nil {
+			detail += fmt.Sprintf(" • CA %d", item.ArmorData.BaseAC+item.ArmorData.MagicBonus)
+		}
+		w.doc.Text(sheetMargin, w.y, 9, false, fmt.Sprintf("%d×", item.Quantity))
+		w.doc.Text(sheetMargin+30, w.y, 9, true, item.Name)
+		w.doc.Text(sheetMargin+250, w.y, 9, false, detail)
+		w.doc.Text(sheetMargin+sheetWidth-60, w.y, 9, false, fmt.Sprintf("%.2f po", float64(itemPriceCP(item)*int64(item.Quantity))/100))
+		w.y += 13
+	}
+
+	w.y += 6
+	w.paragraph(sheetMargin, 10, true, fmt.Sprintf("Monedas: %d ppt • %d po • %d pe • %d pp • %d pc",
+		currency.Platinum, currency.Gold, currency.Electrum, currency.Silver, currency.Copper))
+	w.paragraph(sheetMargin, 10, true, fmt.Sprintf("Valor total: %.2f po", derived.InventoryValue))
+	w.paragraph(sheetMargin, 10, true, fmt.Sprintf("Carga: %.1f / %.0f lb", derived.Encumbrance.CarriedWeight, derived.Encumbrance.CarryingCapacity))
+
+	return w.doc.Bytes()
+}
@@ -0,0 +1,230 @@
+// backend/internal/handlers/character_history.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// HISTORIAL DE VERSIONES DE PERSONAJES
+// ===========================
+
+var errVersionNotFound = errors.New("versión no encontrada")
+
+// historyIgnoredFields - campos que no cuentan como cambio de la hoja
+var historyIgnoredFields = map[string]bool{
+	"id": true, "campaignId": true, "userId": true, "createdAt": true, "updatedAt": true,
+}
+
+// GetCharacterHistory - Listar las versiones guardadas de un personaje (más reciente primero)
+func (h *Handler) GetCharacterHistory(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	iter := h.db.Collection("character_history").
+		Where("characterId", "==", charID).
+		OrderBy("createdAt", firestore.Desc).
+		Limit(100).
+		Documents(ctx)
+
+	var versions []models.CharacterVersion
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo historial"})
+			return
+		}
+
+		var version models.CharacterVersion
+		if err := doc.DataTo(&version); err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	if versions == nil {
+		versions = []models.CharacterVersion{}
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// RestoreCharacterVersion - Reescribir el personaje con el estado guardado en una versión
+func (h *Handler) RestoreCharacterVersion(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	charID := c.Param("charId")
+	versionID := c.Param("versionId")
+	ctx := context.Background()
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	versionRef := h.db.Collection("character_history").Doc(versionID)
+	var restored models.Character
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		versionDoc, err := tx.Get(versionRef)
+		if err != nil {
+			return errVersionNotFound
+		}
+		var version models.CharacterVersion
+		if err := versionDoc.DataTo(&version); err != nil {
+			return err
+		}
+		if version.CharacterID != charID {
+			return errVersionNotFound
+		}
+
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		var current models.Character
+		if err := charDoc.DataTo(&current); err != nil {
+			return err
+		}
+
+		// La identidad y pertenencia del personaje no se restauran
+		restored = version.Snapshot
+		restored.ID = current.ID
+		restored.CampaignID = current.CampaignID
+		restored.UserID = current.UserID
+		restored.CreatedAt = current.CreatedAt
+		restored.UpdatedAt = time.Now()
+
+		if err := tx.Set(charRef, restored); err != nil {
+			return err
+		}
+
+		// La restauración también queda en el historial para poder deshacerla
+		historyRef := h.db.Collection("character_history").NewDoc()
+		return tx.Set(historyRef, models.CharacterVersion{
+			ID:           historyRef.ID,
+			CharacterID:  charID,
+			CampaignID:   current.CampaignID,
+			Action:       models.CharacterVersionRestore,
+			EditedBy:     uid,
+			RestoredFrom: versionID,
+			Changes:      diffCharacters(&current, &restored),
+			Snapshot:     current,
+			CreatedAt:    restored.UpdatedAt,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, errVersionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error restaurando versión"})
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+restored.CampaignID)
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// recordCharacterVersionInTx guarda el estado previo y el diff de una edición en la
+// misma transacción que la aplica (después de todas las lecturas). Sin cambios no
+// guarda nada.
+func (h *Handler) recordCharacterVersionInTx(tx *firestore.Transaction, before, after *models.Character, uid, action string) error {
+	changes := diffCharacters(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	historyRef := h.db.Collection("character_history").NewDoc()
+	return tx.Create(historyRef, models.CharacterVersion{
+		ID:          historyRef.ID,
+		CharacterID: before.ID,
+		CampaignID:  before.CampaignID,
+		Action:      action,
+		EditedBy:    uid,
+		Changes:     changes,
+		Snapshot:    *before,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// characterAfterUpdates aplica en memoria las actualizaciones (rutas de primer nivel)
+// sobre una copia del personaje, para calcular el diff sin volver a leerlo
+func characterAfterUpdates(before *models.Character, updates []firestore.Update) models.Character {
+	fields := characterFields(before)
+	for _, update := range updates {
+		raw, err := json.Marshal(update.Value)
+		if err != nil {
+			continue
+		}
+		var value interface{}
+		json.Unmarshal(raw, &value)
+		fields[update.Path] = value
+	}
+
+	var after models.Character
+	if raw, err := json.Marshal(fields); err == nil {
+		json.Unmarshal(raw, &after)
+	}
+	return after
+}
+
+// diffCharacters compara dos estados de la hoja campo a campo (por nombre JSON)
+func diffCharacters(before, after *models.Character) []models.FieldChange {
+	beforeFields := characterFields(before)
+	afterFields := characterFields(after)
+
+	keys := make([]string, 0, len(afterFields))
+	for key := range beforeFields {
+		keys = append(keys, key)
+	}
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []models.FieldChange{}
+	for _, key := range keys {
+		if historyIgnoredFields[key] {
+			continue
+		}
+		if !reflect.DeepEqual(beforeFields[key], afterFields[key]) {
+			changes = append(changes, models.FieldChange{
+				Field:  key,
+				Before: beforeFields[key],
+				After:  afterFields[key],
+			})
+		}
+	}
+
+	return changes
+}
+
+// characterFields convierte el personaje en un mapa genérico comparable
+func characterFields(char *models.Character) map[string]interface{} {
+	fields := map[string]interface{}{}
+	raw, err := json.Marshal(char)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(raw, &fields)
+	return fields
+}
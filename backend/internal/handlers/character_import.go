@@ -0,0 +1,1491 @@
+// backend/internal/handlers/character_import.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// IMPORTACIÓN DE PERSONAJES (D&D BEYOND / FOUNDRY VTT)
+// ===========================
+
+const (
+	maxImportSize     = 5 << 20 // 5 MB
+	maxImportedItems  = 400     // Límite de escrituras por transacción de Firestore
+	formatDnDBeyond   = "dndbeyond"
+	formatFoundry     = "foundry"
+	weaponSimpleMelee = "Simple Melee Weapons"
+)
+
+// importedCharacter es el resultado intermedio de mapear un export externo
+type importedCharacter struct {
+	character models.Character
+	items     []models.InventoryItem
+	currency  models.Currency
+	report    models.ImportReport
+}
+
+func (ic *importedCharacter) unmapped(format string, args ...interface{}) {
+	ic.report.Unmapped = append(ic.report.Unmapped, fmt.Sprintf(format, args...))
+}
+
+func (ic *importedCharacter) warn(format string, args ...interface{}) {
+	ic.report.Warnings = append(ic.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ImportCharacter - Importar un personaje desde un export de D&D Beyond, un actor de Foundry VTT
+// o un bundle JSON exportado por esta aplicación
+// Acepta el JSON en el body o como archivo multipart en el campo "file".
+func (h *Handler) ImportCharacter(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	raw, err := readImportPayload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	imported, err := parseCharacterExport(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	character, err := h.saveImportedCharacter(ctx, campaignID, uid, imported)
+	if err != nil {
+		if err.Error() == "no eres miembro de esta campaña" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "ya tienes un personaje en esta campaña" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error importando personaje"})
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+campaignID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"character": character,
+		"report":    imported.report,
+	})
+}
+
+// readImportPayload lee el JSON a importar desde un archivo multipart o el body
+func readImportPayload(c *gin.Context) ([]byte, error) {
+	var reader io.Reader
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("se requiere un archivo en el campo 'file'")
+		}
+		if fileHeader.Size > maxImportSize {
+			return nil, fmt.Errorf("el archivo supera el máximo de %d MB", maxImportSize>>20)
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer el archivo")
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		reader = c.Request.Body
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(reader, maxImportSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el contenido")
+	}
+	if len(raw) > maxImportSize {
+		return nil, fmt.Errorf("el archivo supera el máximo de %d MB", maxImportSize>>20)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("contenido vacío")
+	}
+
+	return raw, nil
+}
+
+// parseCharacterExport detecta el formato del export y lo mapea
+func parseCharacterExport(raw []byte) (*importedCharacter, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("JSON inválido: %v", err)
+	}
+
+	// Bundle exportado por esta aplicación
+	if format, ok := probe["format"]; ok && string(format) == `"`+exportFormat+`"` {
+		return parseNativeExport(raw)
+	}
+
+	// D&D Beyond envuelve el personaje en {"data": {...}}; Foundry < v10 usa "data" para el sistema
+	if data, ok := probe["data"]; ok {
+		var inner map[string]json.RawMessage
+		if json.Unmarshal(data, &inner) == nil {
+			if _, ok := inner["stats"]; ok {
+				return parseDDBCharacter(data)
+			}
+			if _, ok := inner["abilities"]; ok {
+				return parseFoundryActor(raw)
+			}
+		}
+	}
+
+	if _, ok := probe["stats"]; ok {
+		if _, ok := probe["classes"]; ok {
+			return parseDDBCharacter(raw)
+		}
+	}
+
+	if _, ok := probe["system"]; ok {
+		return parseFoundryActor(raw)
+	}
+
+	return nil, fmt.Errorf("formato no reconocido: se espera un export de D&D Beyond o un actor de Foundry VTT")
+}
+
+// saveImportedCharacter guarda personaje, items y monedas en una sola transacción
+func (h *Handler) saveImportedCharacter(ctx context.Context, campaignID, uid string, imported *importedCharacter) (*models.Character, error) {
+	charRef := h.db.Collection("characters").NewDoc()
+
+	items := imported.items
+	if len(items) > maxImportedItems {
+		imported.warn("solo se importaron %d de %d items", maxImportedItems, len(items))
+		items = items[:maxImportedItems]
+	}
+	imported.report.ImportedItems = len(items)
+
+	character := imported.character
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		memberIter := tx.Documents(h.db.Collection("event_members").
+			Where("campaignId", "==", campaignID).
+			Where("userId", "==", uid).
+			Limit(1))
+
+		if _, err := memberIter.Next(); err == iterator.Done {
+			return fmt.Errorf("no eres miembro de esta campaña")
+		}
+
+		existingIter := tx.Documents(h.db.Collection("characters").
+			Where("campaignId", "==", campaignID).
+			Where("userId", "==", uid).
+			Limit(1))
+
+		if _, err := existingIter.Next(); err != iterator.Done {
+			return fmt.Errorf("ya tienes un personaje en esta campaña")
+		}
+
+		now := time.Now()
+		character.ID = charRef.ID
+		character.CampaignID = campaignID
+		character.UserID = uid
+		character.CreatedAt = now
+		character.UpdatedAt = now
+
+		if err := tx.Set(charRef, character); err != nil {
+			return err
+		}
+
+		// Versión inicial: restaurarla devuelve la hoja al estado importado
+		historyRef := h.db.Collection("character_history").NewDoc()
+		if err := tx.Create(historyRef, models.CharacterVersion{
+			ID:          historyRef.ID,
+			CharacterID: charRef.ID,
+			CampaignID:  campaignID,
+			Action:      models.CharacterVersionImport,
+			EditedBy:    uid,
+			Changes:     []models.FieldChange{},
+			Snapshot:    character,
+			CreatedAt:   now,
+		}); err != nil {
+			return err
+		}
+
+		itemRefs := reassignItemIDs(items, h.db.Collection("inventory_items"))
+		for i, item := range items {
+			itemRef := itemRefs[i]
+			item.CharacterID = charRef.ID
+			item.CampaignID = campaignID
+			item.CreatedAt = now
+			item.UpdatedAt = now
+
+			if err := tx.Set(itemRef, item); err != nil {
+				return err
+			}
+		}
+
+		return tx.Set(h.db.Collection("currencies").Doc(charRef.ID), imported.currency)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &character, nil
+}
+
+// finalizeImportedCharacter completa los campos derivados y normaliza listas
+func finalizeImportedCharacter(ic *importedCharacter) {
+	clampImportedCharacter(ic)
+	char := &ic.character
+
+	if char.Experience < xpForLevel(char.Level) {
+		char.Experience = xpForLevel(char.Level)
+	}
+	char.Initiative = abilityModifier(char.AbilityScores.Dexterity)
+	char.Conditions = []string{}
+
+	char.Languages = uniqueStrings(emptyIfNil(char.Languages))
+	char.ToolProficiencies = uniqueStrings(emptyIfNil(char.ToolProficiencies))
+	char.WeaponProficiencies = uniqueStrings(emptyIfNil(char.WeaponProficiencies))
+	char.ArmorProficiencies = uniqueStrings(emptyIfNil(char.ArmorProficiencies))
+	if char.Feats == nil {
+		char.Feats = []models.Feat{}
+	}
+	char.Features = prepareFeatures(char.Features, nil)
+	char.Resources = []models.Resource{}
+	char.HitDice = prepareHitDice(nil, nil, char.Class, char.Level)
+	if char.Skills == nil {
+		char.Skills = buildSkillList(nil, nil)
+	}
+}
+
+// clampImportedCharacter lleva nombre, clase, nivel, características, puntos de golpe y
+// CA a los rangos que acepta la API, y valida los items como CreateItem (los inválidos se
+// descartan con un aviso). También se aplica a los bundles nativos, que no pasan por
+// finalizeImportedCharacter para no perder recursos, condiciones ni dados de golpe.
+func clampImportedCharacter(ic *importedCharacter) {
+	char := &ic.character
+
+	if char.Name == "" {
+		char.Name = "Personaje importado"
+		ic.warn("el export no tiene nombre")
+	}
+	char.Name = truncateText(char.Name, 50)
+	if char.Class == "" {
+		char.Class = "Aventurero"
+		ic.warn("no se encontró la clase")
+	}
+	char.Class = truncateText(char.Class, 50)
+	if char.Level < 1 {
+		char.Level = 1
+	}
+	if char.Level > 20 {
+		char.Level = 20
+	}
+	if char.MaxHP < 1 {
+		char.MaxHP = 1
+	}
+	if char.MaxHP > 999 {
+		char.MaxHP = 999
+	}
+	if char.CurrentHP > char.MaxHP {
+		char.CurrentHP = char.MaxHP
+	}
+	if char.CurrentHP < 0 {
+		char.CurrentHP = 0
+	}
+	char.TemporaryHP = minInt(maxInt(char.TemporaryHP, 0), 999)
+	for _, score := range []*int{
+		&char.AbilityScores.Strength, &char.AbilityScores.Dexterity, &char.AbilityScores.Constitution,
+		&char.AbilityScores.Intelligence, &char.AbilityScores.Wisdom, &char.AbilityScores.Charisma,
+	} {
+		*score = minInt(maxInt(*score, 1), 30)
+	}
+	if char.Speed == 0 {
+		char.Speed = 30
+	}
+	char.Speed = minInt(maxInt(char.Speed, 0), 120)
+	char.Initiative = minInt(maxInt(char.Initiative, -5), 15)
+	char.ProficiencyBonus = (char.Level-1)/4 + 2
+
+	// La sintonía se vuelve a hacer en la mesa: importarla saltearía el límite de 3
+	items := make([]models.InventoryItem, 0, len(ic.items))
+	attuned := 0
+	for _, item := range ic.items {
+		item.Quantity = minInt(maxInt(item.Quantity, 1), MAX_ITEM_QUANTITY)
+		if item.Attuned {
+			item.Attuned = false
+			attuned++
+		}
+		if err := validateItem(&item); err != nil {
+			ic.warn("item %q descartado: %v", item.Name, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	ic.items = items
+	if attuned > 0 {
+		ic.warn("se quitó la sintonía de %d objetos: vuelve a sintonizarlos", attuned)
+	}
+
+	if char.ArmorClass == 0 {
+		char.ArmorClass = deriveArmorClass(char, ic.items)
+		ic.warn("CA calculada a partir de la armadura equipada: %d", char.ArmorClass)
+	}
+	char.ArmorClass = minInt(maxInt(char.ArmorClass, 1), 30)
+}
+
+// buildSkillList genera las 18 skills de 5e marcando competencia y pericia
+func buildSkillList(proficient, expertise map[string]bool) []models.Skill {
+	names := make([]string, 0, len(dnd5eSkills))
+	for name := range dnd5eSkills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	skills := make([]models.Skill, 0, len(names))
+	for _, name := range names {
+		skills = append(skills, models.Skill{
+			Name:       titleCase(name),
+			Ability:    dnd5eSkills[name],
+			Proficient: proficient[name] || expertise[name],
+			Expertise:  expertise[name],
+		})
+	}
+	return skills
+}
+
+// ===========================
+// D&D BEYOND
+// ===========================
+
+type ddbStat struct {
+	ID    int  `json:"id"`
+	Value *int `json:"value"`
+}
+
+type ddbModifier struct {
+	Type                string `json:"type"`
+	SubType             string `json:"subType"`
+	FriendlySubtypeName string `json:"friendlySubtypeName"`
+	Value               *int   `json:"value"`
+}
+
+type ddbDefinitionRef struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	RequiredLevel int    `json:"requiredLevel"`
+}
+
+type ddbItemDefinition struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"`
+	FilterType  string   `json:"filterType"`
+	Cost        *float64 `json:"cost"`
+	Weight      float64  `json:"weight"`
+	Magic       bool     `json:"magic"`
+	Rarity      string   `json:"rarity"`
+	CanAttune   bool     `json:"canAttune"`
+	Damage      *struct {
+		DiceString string `json:"diceString"`
+	} `json:"damage"`
+	DamageType          string `json:"damageType"`
+	CategoryID          int    `json:"categoryId"` // 1 simple, 2 marcial
+	AttackType          int    `json:"attackType"` // 1 cuerpo a cuerpo, 2 a distancia
+	Range               int    `json:"range"`
+	LongRange           int    `json:"longRange"`
+	ArmorClass          int    `json:"armorClass"`
+	ArmorTypeID         int    `json:"armorTypeId"` // 1 ligera, 2 media, 3 pesada, 4 escudo
+	StealthCheck        int    `json:"stealthCheck"`
+	StrengthRequirement *int   `json:"strengthRequirement"`
+	Properties          []struct {
+		Name  string `json:"name"`
+		Notes string `json:"notes"`
+	} `json:"properties"`
+	GrantedModifiers []ddbModifier `json:"grantedModifiers"`
+}
+
+type ddbCharacter struct {
+	Name          string    `json:"name"`
+	Stats         []ddbStat `json:"stats"`
+	BonusStats    []ddbStat `json:"bonusStats"`
+	OverrideStats []ddbStat `json:"overrideStats"`
+	Race          *struct {
+		FullName     string `json:"fullName"`
+		BaseRaceName string `json:"baseRaceName"`
+		SubRaceShort string `json:"subRaceShortName"`
+		WeightSpeeds *struct {
+			Normal struct {
+				Walk int `json:"walk"`
+			} `json:"normal"`
+		} `json:"weightSpeeds"`
+		RacialTraits []struct {
+			Definition ddbDefinitionRef `json:"definition"`
+		} `json:"racialTraits"`
+	} `json:"race"`
+	Classes []struct {
+		Level      int `json:"level"`
+		Definition struct {
+			Name string `json:"name"`
+		} `json:"definition"`
+		SubclassDefinition *struct {
+			Name string `json:"name"`
+		} `json:"subclassDefinition"`
+		ClassFeatures []struct {
+			Definition ddbDefinitionRef `json:"definition"`
+		} `json:"classFeatures"`
+	} `json:"classes"`
+	BaseHitPoints      int  `json:"baseHitPoints"`
+	BonusHitPoints     *int `json:"bonusHitPoints"`
+	OverrideHitPoints  *int `json:"overrideHitPoints"`
+	RemovedHitPoints   int  `json:"removedHitPoints"`
+	TemporaryHitPoints int  `json:"temporaryHitPoints"`
+	CurrentXP          int  `json:"currentXp"`
+	AlignmentID        *int `json:"alignmentId"`
+	Background         *struct {
+		Definition *struct {
+			Name        string `json:"name"`
+			FeatureName string `json:"featureName"`
+		} `json:"definition"`
+	} `json:"background"`
+	DeathSaves *struct {
+		FailCount    *int `json:"failCount"`
+		SuccessCount *int `json:"successCount"`
+	} `json:"deathSaves"`
+	Modifiers  map[string][]ddbModifier `json:"modifiers"`
+	Inventory  []ddbInventoryEntry      `json:"inventory"`
+	Currencies *struct {
+		CP int `json:"cp"`
+		SP int `json:"sp"`
+		EP int `json:"ep"`
+		GP int `json:"gp"`
+		PP int `json:"pp"`
+	} `json:"currencies"`
+	Feats []struct {
+		Definition ddbDefinitionRef `json:"definition"`
+	} `json:"feats"`
+	Spells      map[string]json.RawMessage `json:"spells"`
+	ClassSpells []json.RawMessage          `json:"classSpells"`
+	Notes       map[string]interface{}     `json:"notes"`
+	Traits      map[string]interface{}     `json:"traits"`
+}
+
+type ddbInventoryEntry struct {
+	Quantity   int               `json:"quantity"`
+	Equipped   bool              `json:"equipped"`
+	IsAttuned  bool              `json:"isAttuned"`
+	Definition ddbItemDefinition `json:"definition"`
+}
+
+// ddbAbilityKeys - IDs de stats de D&D Beyond (1 = STR ... 6 = CHA)
+var ddbAbilityKeys = map[int]string{
+	1: "strength", 2: "dexterity", 3: "constitution",
+	4: "intelligence", 5: "wisdom", 6: "charisma",
+}
+
+// ddbAlignments - alignmentId de D&D Beyond
+var ddbAlignments = map[int]string{
+	1: "LG", 2: "NG", 3: "CG", 4: "LN", 5: "N", 6: "CN", 7: "LE", 8: "NE", 9: "CE",
+}
+
+func parseDDBCharacter(raw []byte) (*importedCharacter, error) {
+	var ddb ddbCharacter
+	if err := json.Unmarshal(raw, &ddb); err != nil {
+		return nil, fmt.Errorf("export de D&D Beyond inválido: %v", err)
+	}
+
+	ic := &importedCharacter{report: models.ImportReport{
+		Format:   formatDnDBeyond,
+		Unmapped: []string{},
+		Warnings: []string{},
+	}}
+	char := &ic.character
+	char.Name = strings.TrimSpace(ddb.Name)
+
+	// ===== Ability scores: base + bonus + mejoras; override reemplaza =====
+	scores := map[string]int{}
+	for _, st := range ddb.Stats {
+		if key, ok := ddbAbilityKeys[st.ID]; ok && st.Value != nil {
+			scores[key] = *st.Value
+		}
+	}
+	for _, st := range ddb.BonusStats {
+		if key, ok := ddbAbilityKeys[st.ID]; ok && st.Value != nil {
+			scores[key] += *st.Value
+		}
+	}
+
+	proficientSkills := map[string]bool{}
+	expertiseSkills := map[string]bool{}
+	backgroundSkills := []string{}
+	setScores := map[string]int{}
+
+	for group, mods := range ddb.Modifiers {
+		if group == "item" {
+			if len(mods) > 0 {
+				ic.unmapped("modifiers.item (%d bonificadores de objetos)", len(mods))
+			}
+			continue
+		}
+
+		for _, mod := range mods {
+			sub := strings.ToLower(mod.SubType)
+			switch mod.Type {
+			case "bonus":
+				if strings.HasSuffix(sub, "-score") && mod.Value != nil {
+					scores[strings.TrimSuffix(sub, "-score")] += *mod.Value
+				}
+			case "set":
+				if strings.HasSuffix(sub, "-score") && mod.Value != nil {
+					key := strings.TrimSuffix(sub, "-score")
+					if *mod.Value > setScores[key] {
+						setScores[key] = *mod.Value
+					}
+				}
+			case "proficiency":
+				skill := strings.ReplaceAll(sub, "-", " ")
+				switch {
+				case strings.HasSuffix(sub, "-saving-throws"):
+					setSavingThrow(&char.SavingThrows, strings.TrimSuffix(sub, "-saving-throws"))
+				case dnd5eSkills[skill] != "":
+					proficientSkills[skill] = true
+					if group == "background" {
+						backgroundSkills = append(backgroundSkills, titleCase(skill))
+					}
+				case sub == "light-armor" || sub == "medium-armor" || sub == "heavy-armor":
+					char.ArmorProficiencies = append(char.ArmorProficiencies, strings.TrimSuffix(sub, "-armor"))
+				case sub == "shields":
+					char.ArmorProficiencies = append(char.ArmorProficiencies, "shield")
+				case sub == "simple-weapons" || sub == "martial-weapons":
+					char.WeaponProficiencies = append(char.WeaponProficiencies, strings.TrimSuffix(sub, "-weapons"))
+				case isToolProficiency(mod.FriendlySubtypeName):
+					char.ToolProficiencies = append(char.ToolProficiencies, mod.FriendlySubtypeName)
+				case mod.FriendlySubtypeName != "":
+					char.WeaponProficiencies = append(char.WeaponProficiencies, mod.FriendlySubtypeName)
+				}
+			case "expertise":
+				skill := strings.ReplaceAll(sub, "-", " ")
+				if dnd5eSkills[skill] != "" {
+					expertiseSkills[skill] = true
+				}
+			case "language":
+				if mod.FriendlySubtypeName != "" {
+					char.Languages = append(char.Languages, mod.FriendlySubtypeName)
+				}
+			}
+		}
+	}
+
+	for key, value := range setScores {
+		if value > scores[key] {
+			scores[key] = value
+		}
+	}
+	for _, st := range ddb.OverrideStats {
+		if key, ok := ddbAbilityKeys[st.ID]; ok && st.Value != nil {
+			scores[key] = *st.Value
+		}
+	}
+	char.AbilityScores = models.AbilityScores{
+		Strength:     scores["strength"],
+		Dexterity:    scores["dexterity"],
+		Constitution: scores["constitution"],
+		Intelligence: scores["intelligence"],
+		Wisdom:       scores["wisdom"],
+		Charisma:     scores["charisma"],
+	}
+	char.Skills = buildSkillList(proficientSkills, expertiseSkills)
+
+	// ===== Clases y nivel =====
+	classNames := []string{}
+	mainLevel := 0
+	for _, class := range ddb.Classes {
+		char.Level += class.Level
+		if class.Level > mainLevel {
+			mainLevel = class.Level
+			classNames = append([]string{class.Definition.Name}, classNames...)
+		} else {
+			classNames = append(classNames, class.Definition.Name)
+		}
+
+		for _, feature := range class.ClassFeatures {
+			if feature.Definition.Name == "" || feature.Definition.RequiredLevel > class.Level {
+				continue
+			}
+			char.Features = append(char.Features, models.Feature{
+				Name:        feature.Definition.Name,
+				Source:      "class",
+				Level:       feature.Definition.RequiredLevel,
+				Description: truncateText(stripHTML(feature.Definition.Description), 2000),
+			})
+		}
+	}
+	char.Class = strings.Join(classNames, "/")
+	if len(ddb.Classes) > 1 {
+		ic.warn("personaje multiclase: se combinó como %s nivel %d", char.Class, char.Level)
+	}
+
+	// ===== HP =====
+	conMod := abilityModifier(char.AbilityScores.Constitution)
+	if ddb.OverrideHitPoints != nil {
+		char.MaxHP = *ddb.OverrideHitPoints
+	} else {
+		char.MaxHP = ddb.BaseHitPoints + conMod*char.Level
+		if ddb.BonusHitPoints != nil {
+			char.MaxHP += *ddb.BonusHitPoints
+		}
+	}
+	char.CurrentHP = char.MaxHP - ddb.RemovedHitPoints
+	char.TemporaryHP = ddb.TemporaryHitPoints
+	if ddb.DeathSaves != nil {
+		if ddb.DeathSaves.FailCount != nil {
+			char.DeathSaves.Failures = *ddb.DeathSaves.FailCount
+		}
+		if ddb.DeathSaves.SuccessCount != nil {
+			char.DeathSaves.Successes = *ddb.DeathSaves.SuccessCount
+		}
+	}
+	char.Experience = ddb.CurrentXP
+
+	// ===== Origen =====
+	if ddb.Race != nil {
+		char.Race = ddb.Race.BaseRaceName
+		if ddb.Race.SubRaceShort != "" {
+			char.Subrace = ddb.Race.SubRaceShort
+		}
+		if ddb.Race.WeightSpeeds != nil {
+			char.Speed = ddb.Race.WeightSpeeds.Normal.Walk
+		}
+		for _, trait := range ddb.Race.RacialTraits {
+			if trait.Definition.Name == "" {
+				continue
+			}
+			char.Features = append(char.Features, models.Feature{
+				Name:        trait.Definition.Name,
+				Source:      "race",
+				Description: truncateText(stripHTML(trait.Definition.Description), 2000),
+			})
+		}
+	}
+	if ddb.AlignmentID != nil {
+		char.Alignment = ddbAlignments[*ddb.AlignmentID]
+	}
+	if ddb.Background != nil && ddb.Background.Definition != nil {
+		char.Background = &models.Background{
+			Name:               ddb.Background.Definition.Name,
+			SkillProficiencies: backgroundSkills,
+			Feature:            ddb.Background.Definition.FeatureName,
+		}
+	}
+
+	for _, feat := range ddb.Feats {
+		if feat.Definition.Name == "" {
+			continue
+		}
+		char.Feats = append(char.Feats, models.Feat{
+			Name:        feat.Definition.Name,
+			Description: truncateText(stripHTML(feat.Definition.Description), 2000),
+		})
+	}
+
+	// ===== Inventario =====
+	for _, entry := range ddb.Inventory {
+		item, ok := ddbItemToInventory(entry)
+		if !ok {
+			ic.unmapped("inventory: %s (tipo %q)", entry.Definition.Name, entry.Definition.FilterType)
+			continue
+		}
+		ic.items = append(ic.items, item)
+	}
+
+	// ===== Monedas =====
+	if ddb.Currencies != nil {
+		ic.currency = models.Currency{
+			Copper:   ddb.Currencies.CP,
+			Silver:   ddb.Currencies.SP,
+			Gold:     ddb.Currencies.GP,
+			Electrum: ddb.Currencies.EP,
+			Platinum: ddb.Currencies.PP,
+		}
+	}
+
+	// ===== Campos sin equivalente =====
+	spellCount := len(ddb.ClassSpells)
+	for _, list := range ddb.Spells {
+		var entries []json.RawMessage
+		if json.Unmarshal(list, &entries) == nil {
+			spellCount += len(entries)
+		}
+	}
+	if spellCount > 0 {
+		ic.unmapped("spells (%d listas/conjuros)", spellCount)
+	}
+	if len(ddb.Notes) > 0 {
+		ic.unmapped("notes")
+	}
+	if len(ddb.Traits) > 0 {
+		ic.unmapped("traits (personalidad, ideales, vínculos, defectos)")
+	}
+
+	finalizeImportedCharacter(ic)
+	if err := validateBackgroundSkills(char.Background, char.Skills); err != nil {
+		ic.warn("%v", err)
+		char.Background.SkillProficiencies = []string{}
+	}
+
+	return ic, nil
+}
+
+// ddbItemToInventory mapea un item del inventario de D&D Beyond
+func ddbItemToInventory(entry ddbInventoryEntry) (models.InventoryItem, bool) {
+	def := entry.Definition
+	if def.Name == "" {
+		return models.InventoryItem{}, false
+	}
+
+	item := models.InventoryItem{
+		Name:        truncateText(def.Name, 100),
+		Description: truncateText(stripHTML(def.Description), 1000),
+		Quantity:    entry.Quantity,
+		Weight:      def.Weight,
+		Equipped:    entry.Equipped,
+	}
+	if def.Cost != nil {
+		item.Value = *def.Cost
+		item.PriceCP = gpToCP(*def.Cost)
+	}
+
+	filter := strings.ToLower(def.FilterType)
+	switch {
+	case filter == "weapon":
+		item.Type = models.ItemTypeWeapon
+		weapon := &models.WeaponData{
+			WeaponType: weaponCategory(def.CategoryID == 2, def.AttackType == 2),
+			DamageType: strings.ToLower(def.DamageType),
+		}
+		if def.Damage != nil {
+			weapon.DamageDice = def.Damage.DiceString
+		}
+		for _, prop := range def.Properties {
+			applyWeaponProperty(&weapon.Properties, prop.Name, prop.Notes)
+		}
+		if def.Range > 5 && (weapon.Properties.Thrown || weapon.Properties.Ammunition || def.AttackType == 2) {
+			weapon.Properties.Range = &models.WeaponRange{Normal: def.Range, Max: def.LongRange}
+		}
+		for _, mod := range def.GrantedModifiers {
+			if mod.Type == "bonus" && mod.SubType == "magic" && mod.Value != nil {
+				weapon.MagicBonus = *mod.Value
+			}
+		}
+		item.WeaponData = weapon
+	case filter == "armor":
+		item.Type = models.ItemTypeArmor
+		armor := &models.ArmorData{
+			BaseAC:              def.ArmorClass,
+			StealthDisadvantage: def.StealthCheck == 2,
+		}
+		switch def.ArmorTypeID {
+		case 1:
+			armor.ArmorType, armor.DexModifier = "Light Armor", "full"
+		case 2:
+			armor.ArmorType, armor.DexModifier = "Medium Armor", "max2"
+		case 3:
+			armor.ArmorType, armor.DexModifier = "Heavy Armor", "none"
+		case 4:
+			item.Type = models.ItemTypeShield
+			armor.ArmorType, armor.DexModifier = "Shield", "none"
+		}
+		if def.StrengthRequirement != nil {
+			armor.StrengthRequirement = *def.StrengthRequirement
+		}
+		for _, mod := range def.GrantedModifiers {
+			if mod.Type == "bonus" && mod.SubType == "armor-class" && mod.Value != nil {
+				armor.MagicBonus = *mod.Value
+			}
+		}
+		item.ArmorData = armor
+	case filter == "potion" || filter == "scroll":
+		item.Type = models.ItemTypeConsumable
+	case strings.Contains(strings.ToLower(def.Type), "tool") || isToolProficiency(def.Name):
+		item.Type = models.ItemTypeTool
+	case strings.Contains(strings.ToLower(def.Type), "gemstone") || strings.Contains(strings.ToLower(def.Type), "art"):
+		item.Type = models.ItemTypeTreasure
+	case filter == "other gear" || filter == "wondrous item" || filter == "ring" ||
+		filter == "rod" || filter == "staff" || filter == "wand":
+		item.Type = models.ItemTypeOther
+	default:
+		return models.InventoryItem{}, false
+	}
+
+	if def.Magic || def.CanAttune {
+		item.MagicData = &models.MagicItemData{
+			Rarity:             strings.ToLower(def.Rarity),
+			RequiresAttunement: def.CanAttune,
+		}
+		if validateMagicData(item.MagicData) != nil {
+			item.MagicData.Rarity = models.RarityCommon
+		}
+		item.Attuned = def.CanAttune && entry.IsAttuned
+	}
+
+	return item, true
+}
+
+// ===========================
+// FOUNDRY VTT (sistema dnd5e)
+// ===========================
+
+// foundrySkillKeys - claves de skills de Foundry
+var foundrySkillKeys = map[string]string{
+	"acr": "acrobatics", "ani": "animal handling", "arc": "arcana", "ath": "athletics",
+	"dec": "deception", "his": "history", "ins": "insight", "itm": "intimidation",
+	"inv": "investigation", "med": "medicine", "nat": "nature", "prc": "perception",
+	"prf": "performance", "per": "persuasion", "rel": "religion", "slt": "sleight of hand",
+	"ste": "stealth", "sur": "survival",
+}
+
+var foundryAbilityKeys = map[string]string{
+	"str": "strength", "dex": "dexterity", "con": "constitution",
+	"int": "intelligence", "wis": "wisdom", "cha": "charisma",
+}
+
+var foundryAlignments = map[string]string{
+	"lawful good": "LG", "neutral good": "NG", "chaotic good": "CG",
+	"lawful neutral": "LN", "neutral": "N", "true neutral": "N", "chaotic neutral": "CN",
+	"lawful evil": "LE", "neutral evil": "NE", "chaotic evil": "CE", "unaligned": "unaligned",
+}
+
+var foundryWeaponProps = map[string]string{
+	"lgt": "light", "fin": "finesse", "thr": "thrown", "two": "two-handed",
+	"ver": "versatile", "rch": "reach", "lod": "loading", "hvy": "heavy", "amm": "ammunition",
+}
+
+func parseFoundryActor(raw []byte) (*importedCharacter, error) {
+	var actor map[string]interface{}
+	if err := json.Unmarshal(raw, &actor); err != nil {
+		return nil, fmt.Errorf("actor de Foundry inválido: %v", err)
+	}
+
+	if t := asString(actor["type"]); t != "" && t != "character" {
+		return nil, fmt.Errorf("solo se pueden importar actores de tipo 'character' (recibido %q)", t)
+	}
+
+	sys, _ := actor["system"].(map[string]interface{})
+	if sys == nil {
+		sys, _ = actor["data"].(map[string]interface{})
+	}
+	if sys == nil {
+		return nil, fmt.Errorf("actor de Foundry sin datos de sistema")
+	}
+
+	ic := &importedCharacter{report: models.ImportReport{
+		Format:   formatFoundry,
+		Unmapped: []string{},
+		Warnings: []string{},
+	}}
+	char := &ic.character
+	char.Name = strings.TrimSpace(asString(actor["name"]))
+
+	// ===== Ability scores y salvaciones =====
+	scores := map[string]int{}
+	for key, name := range foundryAbilityKeys {
+		scores[name] = asInt(getPath(sys, "abilities", key, "value"))
+		if asFloat(getPath(sys, "abilities", key, "proficient")) >= 1 {
+			setSavingThrow(&char.SavingThrows, name)
+		}
+	}
+	char.AbilityScores = models.AbilityScores{
+		Strength:     scores["strength"],
+		Dexterity:    scores["dexterity"],
+		Constitution: scores["constitution"],
+		Intelligence: scores["intelligence"],
+		Wisdom:       scores["wisdom"],
+		Charisma:     scores["charisma"],
+	}
+
+	// ===== Skills =====
+	proficientSkills := map[string]bool{}
+	expertiseSkills := map[string]bool{}
+	halfProficiency := 0
+	for key, name := range foundrySkillKeys {
+		value := asFloat(getPath(sys, "skills", key, "value"))
+		switch {
+		case value >= 2:
+			expertiseSkills[name] = true
+		case value >= 1:
+			proficientSkills[name] = true
+		case value > 0:
+			halfProficiency++
+		}
+	}
+	if halfProficiency > 0 {
+		ic.unmapped("skills con media competencia (%d)", halfProficiency)
+	}
+	char.Skills = buildSkillList(proficientSkills, expertiseSkills)
+
+	// ===== Atributos =====
+	char.CurrentHP = asInt(getPath(sys, "attributes", "hp", "value"))
+	char.MaxHP = asInt(getPath(sys, "attributes", "hp", "max"))
+	char.TemporaryHP = asInt(getPath(sys, "attributes", "hp", "temp"))
+	if char.MaxHP == 0 {
+		char.MaxHP = char.CurrentHP
+		ic.warn("HP máximo calculado por Foundry: se usó el HP actual (%d)", char.CurrentHP)
+	}
+	if asString(getPath(sys, "attributes", "ac", "calc")) == "flat" {
+		char.ArmorClass = asInt(getPath(sys, "attributes", "ac", "flat"))
+	}
+	char.Speed = asInt(getPath(sys, "attributes", "movement", "walk"))
+	char.DeathSaves = models.DeathSaves{
+		Successes: asInt(getPath(sys, "attributes", "death", "success")),
+		Failures:  asInt(getPath(sys, "attributes", "death", "failure")),
+	}
+	char.Experience = asInt(getPath(sys, "details", "xp", "value"))
+
+	// ===== Detalles =====
+	if race, ok := getPath(sys, "details", "race").(string); ok && !looksLikeFoundryID(race) {
+		char.Race = race
+	}
+	if background, ok := getPath(sys, "details", "background").(string); ok && background != "" && !looksLikeFoundryID(background) {
+		char.Background = &models.Background{Name: background, SkillProficiencies: []string{}}
+	}
+	if alignment := strings.ToLower(strings.TrimSpace(asString(getPath(sys, "details", "alignment")))); alignment != "" {
+		if code, ok := foundryAlignments[alignment]; ok {
+			char.Alignment = code
+		} else {
+			ic.unmapped("details.alignment (%q)", alignment)
+		}
+	}
+	for _, field := range []string{"biography", "trait", "ideal", "bond", "flaw"} {
+		if value := getPath(sys, "details", field); value != nil && asString(value) != "" {
+			ic.unmapped("details.%s", field)
+		}
+	}
+
+	// ===== Rasgos: idiomas y competencias =====
+	for _, lang := range foundryTraitValues(sys, "languages") {
+		char.Languages = append(char.Languages, titleCase(lang))
+	}
+	for _, prof := range foundryTraitValues(sys, "weaponProf") {
+		switch prof {
+		case "sim":
+			char.WeaponProficiencies = append(char.WeaponProficiencies, "simple")
+		case "mar":
+			char.WeaponProficiencies = append(char.WeaponProficiencies, "martial")
+		default:
+			char.WeaponProficiencies = append(char.WeaponProficiencies, titleCase(prof))
+		}
+	}
+	for _, prof := range foundryTraitValues(sys, "armorProf") {
+		switch prof {
+		case "lgt":
+			char.ArmorProficiencies = append(char.ArmorProficiencies, "light")
+		case "med":
+			char.ArmorProficiencies = append(char.ArmorProficiencies, "medium")
+		case "hvy":
+			char.ArmorProficiencies = append(char.ArmorProficiencies, "heavy")
+		case "shl":
+			char.ArmorProficiencies = append(char.ArmorProficiencies, "shield")
+		}
+	}
+	for _, tool := range foundryTraitValues(sys, "toolProf") {
+		char.ToolProficiencies = append(char.ToolProficiencies, titleCase(tool))
+	}
+	if tools, ok := sys["tools"].(map[string]interface{}); ok {
+		for key := range tools {
+			char.ToolProficiencies = append(char.ToolProficiencies, titleCase(key))
+		}
+	}
+
+	// ===== Monedas =====
+	ic.currency = models.Currency{
+		Copper:   asInt(getPath(sys, "currency", "cp")),
+		Silver:   asInt(getPath(sys, "currency", "sp")),
+		Gold:     asInt(getPath(sys, "currency", "gp")),
+		Electrum: asInt(getPath(sys, "currency", "ep")),
+		Platinum: asInt(getPath(sys, "currency", "pp")),
+	}
+
+	// ===== Items embebidos (clases, rasgos, equipo) =====
+	classNames := []string{}
+	mainLevel := 0
+	spellCount := 0
+	items, _ := actor["items"].([]interface{})
+
+	for _, rawItem := range items {
+		item, ok := rawItem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		itemSys, _ := item["system"].(map[string]interface{})
+		if itemSys == nil {
+			itemSys, _ = item["data"].(map[string]interface{})
+		}
+		name := asString(item["name"])
+		itemType := asString(item["type"])
+
+		switch itemType {
+		case "class":
+			levels := asInt(itemSys["levels"])
+			if levels == 0 {
+				levels = 1
+			}
+			char.Level += levels
+			if levels > mainLevel {
+				mainLevel = levels
+				classNames = append([]string{name}, classNames...)
+			} else {
+				classNames = append(classNames, name)
+			}
+		case "subclass":
+			// La subclase queda implícita en los rasgos
+		case "race":
+			char.Race = name
+		case "background":
+			char.Background = &models.Background{Name: name, SkillProficiencies: []string{}}
+		case "feat":
+			featType := asString(getPath(itemSys, "type", "value"))
+			description := truncateText(stripHTML(asString(getPath(itemSys, "description", "value"))), 2000)
+			if featType == "feat" {
+				char.Feats = append(char.Feats, models.Feat{Name: name, Description: description})
+				continue
+			}
+			source := "other"
+			switch featType {
+			case "class", "race", "background":
+				source = featType
+			}
+			feature := models.Feature{Name: name, Source: source, Description: description}
+			feature.Uses = foundryLimitedUses(itemSys)
+			char.Features = append(char.Features, feature)
+		case "spell":
+			spellCount++
+		case "weapon", "equipment", "consumable", "tool", "loot", "backpack":
+			inv, ok := foundryItemToInventory(name, itemType, itemSys)
+			if !ok {
+				ic.unmapped("items: %s (tipo %q)", name, itemType)
+				continue
+			}
+			ic.items = append(ic.items, inv)
+		default:
+			ic.unmapped("items: %s (tipo %q)", name, itemType)
+		}
+	}
+
+	char.Class = strings.Join(classNames, "/")
+	if len(classNames) > 1 {
+		ic.warn("personaje multiclase: se combinó como %s nivel %d", char.Class, char.Level)
+	}
+	if char.Level == 0 {
+		char.Level = asInt(getPath(sys, "details", "level"))
+	}
+	if spellCount > 0 {
+		ic.unmapped("spells (%d conjuros)", spellCount)
+	}
+	if effects, ok := actor["effects"].([]interface{}); ok && len(effects) > 0 {
+		ic.unmapped("effects (%d efectos activos)", len(effects))
+	}
+
+	finalizeImportedCharacter(ic)
+	return ic, nil
+}
+
+// foundryItemToInventory mapea un item de equipo de Foundry
+func foundryItemToInventory(name, itemType string, sys map[string]interface{}) (models.InventoryItem, bool) {
+	if name == "" {
+		return models.InventoryItem{}, false
+	}
+
+	item := models.InventoryItem{
+		Name:        truncateText(name, 100),
+		Description: truncateText(stripHTML(asString(getPath(sys, "description", "value"))), 1000),
+		Quantity:    asInt(sys["quantity"]),
+		Value:       foundryPrice(sys["price"]),
+		Weight:      foundryWeight(sys["weight"]),
+		Equipped:    asBool(sys["equipped"]),
+	}
+
+	subType := asString(getPath(sys, "type", "value"))
+	props := foundryItemProperties(sys["properties"])
+
+	switch itemType {
+	case "weapon":
+		item.Type = models.ItemTypeWeapon
+		weaponType := subType
+		if weaponType == "" {
+			weaponType = asString(sys["weaponType"])
+		}
+		weapon := &models.WeaponData{
+			WeaponType: weaponCategory(strings.HasPrefix(weaponType, "martial"), strings.HasSuffix(weaponType, "R")),
+			MagicBonus: asInt(sys["magicalBonus"]),
+		}
+		weapon.DamageDice, weapon.DamageType = foundryDamage(sys["damage"])
+		for prop := range props {
+			if name, ok := foundryWeaponProps[prop]; ok {
+				applyWeaponProperty(&weapon.Properties, name, asString(getPath(sys, "damage", "versatile")))
+			}
+		}
+		if normal := asInt(getPath(sys, "range", "value")); normal > 5 {
+			weapon.Properties.Range = &models.WeaponRange{Normal: normal, Max: asInt(getPath(sys, "range", "long"))}
+		}
+		item.WeaponData = weapon
+	case "equipment":
+		armorType := subType
+		if armorType == "" {
+			armorType = asString(getPath(sys, "armor", "type"))
+		}
+		armor := &models.ArmorData{
+			BaseAC:              asInt(getPath(sys, "armor", "value")),
+			StrengthRequirement: asInt(sys["strength"]),
+			StealthDisadvantage: asBool(sys["stealth"]) || props["stealthDisadvantage"],
+			MagicBonus:          asInt(getPath(sys, "armor", "magicalBonus")),
+		}
+		switch armorType {
+		case "light":
+			item.Type = models.ItemTypeArmor
+			armor.ArmorType, armor.DexModifier = "Light Armor", "full"
+		case "medium":
+			item.Type = models.ItemTypeArmor
+			armor.ArmorType, armor.DexModifier = "Medium Armor", "max2"
+		case "heavy":
+			item.Type = models.ItemTypeArmor
+			armor.ArmorType, armor.DexModifier = "Heavy Armor", "none"
+		case "shield":
+			item.Type = models.ItemTypeShield
+			armor.ArmorType, armor.DexModifier = "Shield", "none"
+		default:
+			item.Type = models.ItemTypeOther
+			armor = nil
+		}
+		item.ArmorData = armor
+	case "consumable":
+		item.Type = models.ItemTypeConsumable
+	case "tool":
+		item.Type = models.ItemTypeTool
+	case "loot":
+		if subType == "gem" || subType == "art" || subType == "treasure" {
+			item.Type = models.ItemTypeTreasure
+		} else {
+			item.Type = models.ItemTypeOther
+		}
+	case "backpack":
+		item.Type = models.ItemTypeOther
+	default:
+		return models.InventoryItem{}, false
+	}
+
+	return item, true
+}
+
+// foundryDamage obtiene dado y tipo de daño (formato v3 "base" o "parts" anterior)
+func foundryDamage(raw interface{}) (string, string) {
+	damage, _ := raw.(map[string]interface{})
+	if damage == nil {
+		return "", ""
+	}
+
+	if base, ok := damage["base"].(map[string]interface{}); ok {
+		dice := ""
+		if number, denom := asInt(base["number"]), asInt(base["denomination"]); number > 0 && denom > 0 {
+			dice = fmt.Sprintf("%dd%d", number, denom)
+		}
+		damageType := ""
+		if types, ok := base["types"].([]interface{}); ok && len(types) > 0 {
+			damageType = asString(types[0])
+		}
+		return dice, damageType
+	}
+
+	if parts, ok := damage["parts"].([]interface{}); ok && len(parts) > 0 {
+		if part, ok := parts[0].([]interface{}); ok && len(part) > 0 {
+			formula := strings.TrimSpace(strings.Split(asString(part[0]), "+")[0])
+			damageType := ""
+			if len(part) > 1 {
+				damageType = asString(part[1])
+			}
+			return formula, damageType
+		}
+	}
+
+	return "", ""
+}
+
+// foundryLimitedUses mapea usos por descanso corto/largo
+func foundryLimitedUses(sys map[string]interface{}) *models.LimitedUses {
+	max := asInt(getPath(sys, "uses", "max"))
+	if max <= 0 {
+		return nil
+	}
+
+	recharge := ""
+	switch asString(getPath(sys, "uses", "per")) {
+	case "sr":
+		recharge = models.RechargeShortRest
+	case "lr", "day":
+		recharge = models.RechargeLongRest
+	}
+	if recharge == "" {
+		if recovery, ok := getPath(sys, "uses", "recovery").([]interface{}); ok && len(recovery) > 0 {
+			switch asString(getPath(recovery[0], "period")) {
+			case "sr":
+				recharge = models.RechargeShortRest
+			case "lr", "day", "dawn":
+				recharge = models.RechargeLongRest
+			}
+		}
+	}
+	if recharge == "" {
+		return nil
+	}
+
+	current := max - asInt(getPath(sys, "uses", "spent"))
+	if value := getPath(sys, "uses", "value"); value != nil {
+		current = asInt(value)
+	}
+	if current < 0 {
+		current = 0
+	}
+
+	return &models.LimitedUses{Max: max, Current: current, Recharge: recharge}
+}
+
+// foundryWeight lee el peso (número o {value, units} en versiones recientes)
+func foundryWeight(raw interface{}) float64 {
+	if m, ok := raw.(map[string]interface{}); ok {
+		return asFloat(m["value"])
+	}
+	return asFloat(raw)
+}
+
+// foundryPrice convierte el precio (número en po o {value, denomination}) a po
+func foundryPrice(raw interface{}) float64 {
+	if price, ok := raw.(map[string]interface{}); ok {
+		value := asFloat(price["value"])
+		switch asString(price["denomination"]) {
+		case "cp":
+			return value / 100
+		case "sp":
+			return value / 10
+		case "ep":
+			return value / 2
+		case "pp":
+			return value * 10
+		}
+		return value
+	}
+	return asFloat(raw)
+}
+
+// foundryItemProperties soporta el formato array (v3) y objeto (anteriores)
+func foundryItemProperties(raw interface{}) map[string]bool {
+	props := map[string]bool{}
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, p := range v {
+			props[asString(p)] = true
+		}
+	case map[string]interface{}:
+		for key, enabled := range v {
+			if asBool(enabled) {
+				props[key] = true
+			}
+		}
+	}
+	return props
+}
+
+// foundryTraitValues devuelve los valores de un rasgo ({value: [...], custom: "a;b"})
+func foundryTraitValues(sys map[string]interface{}, trait string) []string {
+	values := []string{}
+	if list, ok := getPath(sys, "traits", trait, "value").([]interface{}); ok {
+		for _, v := range list {
+			if s := asString(v); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	for _, custom := range strings.Split(asString(getPath(sys, "traits", trait, "custom")), ";") {
+		if custom = strings.TrimSpace(custom); custom != "" {
+			values = append(values, custom)
+		}
+	}
+	return values
+}
+
+func looksLikeFoundryID(value string) bool {
+	return len(value) == 16 && !strings.Contains(value, " ")
+}
+
+// ===========================
+// HELPERS COMPARTIDOS
+// ===========================
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML elimina etiquetas HTML y compacta espacios
+func stripHTML(html string) string {
+	text := htmlTagPattern.ReplaceAllString(html, " ")
+	text = strings.NewReplacer("&nbsp;", " ", "&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&#39;", "'").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func truncateText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max])
+}
+
+func titleCase(value string) string {
+	words := strings.Fields(strings.ReplaceAll(value, "-", " "))
+	for i, w := range words {
+		runes := []rune(w)
+		runes[0] = []rune(strings.ToUpper(string(runes[0])))[0]
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+func weaponCategory(martial, ranged bool) string {
+	switch {
+	case martial && ranged:
+		return "Martial Ranged Weapons"
+	case martial:
+		return "Martial Melee Weapons"
+	case ranged:
+		return "Simple Ranged Weapons"
+	default:
+		return weaponSimpleMelee
+	}
+}
+
+// applyWeaponProperty activa una propiedad de arma por nombre
+func applyWeaponProperty(props *models.WeaponProperties, name, notes string) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "light":
+		props.Light = true
+	case "finesse":
+		props.Finesse = true
+	case "thrown":
+		props.Thrown = true
+	case "two-handed", "two handed":
+		props.TwoHanded = true
+	case "versatile":
+		props.Versatile = strings.TrimSpace(notes)
+		if props.Versatile == "" {
+			props.Versatile = "true"
+		}
+	case "reach":
+		props.Reach = true
+	case "loading":
+		props.Loading = true
+	case "heavy":
+		props.Heavy = true
+	case "ammunition":
+		props.Ammunition = true
+	}
+}
+
+func setSavingThrow(saves *models.SavingThrows, ability string) {
+	switch ability {
+	case "strength":
+		saves.Strength = true
+	case "dexterity":
+		saves.Dexterity = true
+	case "constitution":
+		saves.Constitution = true
+	case "intelligence":
+		saves.Intelligence = true
+	case "wisdom":
+		saves.Wisdom = true
+	case "charisma":
+		saves.Charisma = true
+	}
+}
+
+var toolKeywords = []string{
+	"tools", "kit", "supplies", "utensils", "set", "instrument",
+	"bagpipes", "drum", "dulcimer", "flute", "lute", "lyre", "horn", "shawm", "viol",
+}
+
+func isToolProficiency(name string) bool {
+	lower := strings.ToLower(name)
+	for _, keyword := range toolKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPath navega un JSON genérico por claves
+func getPath(value interface{}, keys ...string) interface{} {
+	current := value
+	for _, key := range keys {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+func asString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func asFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f
+	case map[string]interface{}:
+		return asFloat(v["value"])
+	}
+	return 0
+}
+
+func asInt(value interface{}) int {
+	return int(asFloat(value))
+}
+
+func asBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	}
+	return false
+}
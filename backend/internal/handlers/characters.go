@@ -3,8 +3,11 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/firestore"
@@ -34,6 +37,11 @@ func (h *Handler) CreateCharacter(c *gin.Context) {
 		return
 	}
 
+	if err := validateBackgroundSkills(req.Background, req.Skills); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	charRef := h.db.Collection("characters").NewDoc()
 
 	// ✅ USAR TRANSACCIÓN PARA EVITAR DUPLICADOS
@@ -62,44 +70,8 @@ func (h *Handler) CreateCharacter(c *gin.Context) {
 			return fmt.Errorf("ya tienes un personaje en esta campaña")
 		}
 
-		// 3. Calcular proficiency bonus automáticamente
-		proficiencyBonus := (req.Level-1)/4 + 2
-
-		// 4. Validar y preparar skills
-		skills := req.Skills
-		if skills == nil {
-			skills = []models.Skill{}
-		}
-
-		// 5. Crear personaje con TODOS los campos del Nivel 1
-		character := models.Character{
-			ID:         charRef.ID,
-			CampaignID: campaignID,
-			UserID:     uid,
-			Name:       req.Name,
-			Class:      req.Class,
-			Level:      req.Level,
-
-			// Combat Stats
-			MaxHP:      req.MaxHP,
-			CurrentHP:  req.MaxHP, // Inicia con HP completo
-			ArmorClass: req.ArmorClass,
-			Initiative: req.Initiative,
-			Speed:      req.Speed,
-			Conditions: []string{}, // ✅ Siempre inicia vacío
-
-			// ===== NIVEL 1: Ability Scores =====
-			AbilityScores: req.AbilityScores,
-
-			// ===== NIVEL 1: Proficiencies =====
-			ProficiencyBonus: proficiencyBonus,
-			SavingThrows:     req.SavingThrows,
-			Skills:           skills,
-
-			// Metadata
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
+		// 3. Crear personaje con TODOS los campos del Nivel 1
+		character := buildCharacter(req, charRef.ID, campaignID, uid)
 
 		return tx.Set(charRef, character)
 	})
@@ -183,26 +155,37 @@ func (h *Handler) UpdateCharacter(c *gin.Context) {
 	}
 
 	// Verificar permisos: solo el dueño o el DM pueden editar
-	campaignDoc, err := h.db.Collection("events").Doc(char.CampaignID).Get(ctx)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
-		return
-	}
-
+	// (los personajes del baúl no tienen campaña: solo el dueño)
 	var campaign models.Campaign
-	campaignDoc.DataTo(&campaign)
+	if char.CampaignID != "" {
+		campaignDoc, err := h.db.Collection("events").Doc(char.CampaignID).Get(ctx)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+			return
+		}
+		campaignDoc.DataTo(&campaign)
+	}
 
 	if char.UserID != uid && campaign.DmID != uid {
 		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para editar este personaje"})
 		return
 	}
 
-	var req models.CreateCharacterRequest
+	var req models.UpdateCharacterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	background := char.Background
+	if req.Background != nil {
+		background = req.Background
+	}
+	if err := validateBackgroundSkills(background, req.Skills); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Calcular proficiency bonus basado en el nivel
 	proficiencyBonus := (req.Level-1)/4 + 2
 
@@ -234,19 +217,91 @@ func (h *Handler) UpdateCharacter(c *gin.Context) {
 		{Path: "savingThrows", Value: req.SavingThrows},
 		{Path: "skills", Value: skills},
 
+		// Dados de golpe (los valores actuales se conservan)
+		{Path: "hitDice", Value: prepareHitDice(req.HitDice, char.HitDice, req.Class, req.Level)},
+
 		// Metadata
 		{Path: "updatedAt", Value: time.Now()},
 	}
 
-	if _, err := h.db.Collection("characters").Doc(charID).Update(ctx, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando personaje"})
-		return
+	// Origen, competencias, dotes y rasgos: solo los que vienen en la request (el
+	// cliente básico no los envía y no deben borrarse)
+	if req.Race != nil {
+		updates = append(updates, firestore.Update{Path: "race", Value: *req.Race})
+	}
+	if req.Subrace != nil {
+		updates = append(updates, firestore.Update{Path: "subrace", Value: *req.Subrace})
+	}
+	if req.Background != nil {
+		updates = append(updates, firestore.Update{Path: "background", Value: req.Background})
+	}
+	if req.Alignment != nil {
+		updates = append(updates, firestore.Update{Path: "alignment", Value: *req.Alignment})
+	}
+	for path, values := range map[string][]string{
+		"languages":           req.Languages,
+		"toolProficiencies":   req.ToolProficiencies,
+		"weaponProficiencies": req.WeaponProficiencies,
+		"armorProficiencies":  req.ArmorProficiencies,
+	} {
+		if values != nil {
+			updates = append(updates, firestore.Update{Path: path, Value: values})
+		}
+	}
+	if req.Feats != nil {
+		updates = append(updates, firestore.Update{Path: "feats", Value: req.Feats})
+	}
+	if req.Features != nil {
+		// Los usos actuales de los rasgos existentes se conservan
+		updates = append(updates, firestore.Update{Path: "features", Value: prepareFeatures(req.Features, char.Features)})
 	}
 
-	// Obtener personaje actualizado
-	updatedDoc, _ := h.db.Collection("characters").Doc(charID).Get(ctx)
+	// Recursos: sin la lista se conservan los actuales; solo se recalculan los máximos
+	// por fórmula si cambian el nivel o las características
+	if req.Resources != nil || req.Level != char.Level || req.AbilityScores != char.AbilityScores {
+		updates = append(updates, firestore.Update{Path: "resources", Value: prepareResources(req.Resources, char.Resources, req.Level, req.AbilityScores)})
+	}
+
+	// Si cambia el nivel, mantener consistente la progresión
+	if req.Level != char.Level {
+		if progressionMode(&campaign) == models.ProgressionXP {
+			experience := char.Experience
+			if minXP := xpForLevel(req.Level); experience < minXP {
+				experience = minXP
+			}
+			updates = append(updates,
+				firestore.Update{Path: "experience", Value: experience},
+				firestore.Update{Path: "readyToLevelUp", Value: isReadyToLevelUp(req.Level, experience)},
+			)
+		} else if req.Level > char.Level {
+			updates = append(updates, firestore.Update{Path: "readyToLevelUp", Value: false})
+		}
+	}
+
+	// La edición y la versión anterior (para poder restaurarla) se guardan juntas
+	charRef := h.db.Collection("characters").Doc(charID)
 	var updated models.Character
-	updatedDoc.DataTo(&updated)
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		currentDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		var current models.Character
+		if err := currentDoc.DataTo(&current); err != nil {
+			return err
+		}
+
+		updated = characterAfterUpdates(&current, updates)
+		if err := tx.Update(charRef, updates); err != nil {
+			return err
+		}
+		return h.recordCharacterVersionInTx(tx, &current, &updated, uid, models.CharacterVersionUpdate)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando personaje"})
+		return
+	}
+	h.invalidatePattern(ctx, "characters:"+char.CampaignID)
 
 	c.JSON(http.StatusOK, updated)
 }
@@ -273,12 +328,8 @@ func (h *Handler) DeleteCharacter(c *gin.Context) {
 		return
 	}
 
-	// Verificar permisos
-	campaignDoc, _ := h.db.Collection("events").Doc(char.CampaignID).Get(ctx)
-	var campaign models.Campaign
-	campaignDoc.DataTo(&campaign)
-
-	if char.UserID != uid && campaign.DmID != uid {
+	// Verificar permisos: dueño o DM (los personajes del baúl solo el dueño)
+	if !h.characterEditableBy(ctx, char, uid) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para eliminar este personaje"})
 		return
 	}
@@ -288,5 +339,295 @@ func (h *Handler) DeleteCharacter(c *gin.Context) {
 		return
 	}
 
+	// Historial de XP, versiones y movimientos de monedas del personaje
+	h.deleteDocsWhere(ctx, "xp_history", "characterId", charID)
+	h.deleteDocsWhere(ctx, "character_history", "characterId", charID)
+	h.deleteDocsWhere(ctx, "currency_ledger", "characterId", charID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Personaje eliminado"})
 }
+
+// ===========================
+// HELPERS DE HOJA DE PERSONAJE
+// ===========================
+
+// buildCharacter crea un personaje nuevo a partir del request (campaignID "" = baúl)
+func buildCharacter(req models.CreateCharacterRequest, charID, campaignID, uid string) models.Character {
+	// Calcular proficiency bonus automáticamente
+	proficiencyBonus := (req.Level-1)/4 + 2
+
+	// Validar y preparar skills
+	skills := req.Skills
+	if skills == nil {
+		skills = []models.Skill{}
+	}
+
+	feats := req.Feats
+	if feats == nil {
+		feats = []models.Feat{}
+	}
+
+	return models.Character{
+		ID:         charID,
+		CampaignID: campaignID,
+		UserID:     uid,
+		Name:       req.Name,
+		Class:      req.Class,
+		Level:      req.Level,
+
+		// Origen
+		Race:       req.Race,
+		Subrace:    req.Subrace,
+		Background: req.Background,
+		Alignment:  req.Alignment,
+
+		// Combat Stats
+		MaxHP:      req.MaxHP,
+		CurrentHP:  req.MaxHP, // Inicia con HP completo
+		ArmorClass: req.ArmorClass,
+		Initiative: req.Initiative,
+		Speed:      req.Speed,
+		Conditions: []string{}, // ✅ Siempre inicia vacío
+
+		// ===== NIVEL 1: Ability Scores =====
+		AbilityScores: req.AbilityScores,
+
+		// ===== NIVEL 1: Proficiencies =====
+		ProficiencyBonus: proficiencyBonus,
+		SavingThrows:     req.SavingThrows,
+		Skills:           skills,
+
+		// Idiomas, competencias, dotes y rasgos
+		Languages:           emptyIfNil(req.Languages),
+		ToolProficiencies:   emptyIfNil(req.ToolProficiencies),
+		WeaponProficiencies: emptyIfNil(req.WeaponProficiencies),
+		ArmorProficiencies:  emptyIfNil(req.ArmorProficiencies),
+		Feats:               feats,
+		Features:            prepareFeatures(req.Features, nil),
+
+		// Recursos de clase y dados de golpe (empiezan llenos)
+		Resources: prepareResources(req.Resources, nil, req.Level, req.AbilityScores),
+		HitDice:   prepareHitDice(req.HitDice, nil, req.Class, req.Level),
+
+		// Progresión: inicia con la XP mínima de su nivel
+		Experience: xpForLevel(req.Level),
+
+		// Metadata
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// dnd5eSkills - Skills de 5e (en minúsculas) con su habilidad asociada
+var dnd5eSkills = map[string]string{
+	"acrobatics":      "dexterity",
+	"animal handling": "wisdom",
+	"arcana":          "intelligence",
+	"athletics":       "strength",
+	"deception":       "charisma",
+	"history":         "intelligence",
+	"insight":         "wisdom",
+	"intimidation":    "charisma",
+	"investigation":   "intelligence",
+	"medicine":        "wisdom",
+	"nature":          "intelligence",
+	"perception":      "wisdom",
+	"performance":     "charisma",
+	"persuasion":      "charisma",
+	"religion":        "intelligence",
+	"sleight of hand": "dexterity",
+	"stealth":         "dexterity",
+	"survival":        "wisdom",
+}
+
+// validateBackgroundSkills verifica que las skills otorgadas por el trasfondo existan,
+// no estén repetidas y no contradigan la lista de Skills del personaje
+func validateBackgroundSkills(background *models.Background, skills []models.Skill) error {
+	if background == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(background.SkillProficiencies))
+	for _, name := range background.SkillProficiencies {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := dnd5eSkills[key]; !ok {
+			return fmt.Errorf("skill desconocida en el trasfondo: %s", name)
+		}
+		if seen[key] {
+			return fmt.Errorf("skill repetida en el trasfondo: %s", name)
+		}
+		seen[key] = true
+
+		for _, skill := range skills {
+			if strings.EqualFold(strings.TrimSpace(skill.Name), key) && !skill.Proficient {
+				return fmt.Errorf("el trasfondo otorga competencia en %s pero la skill no está marcada como competente", skill.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// prepareFeatures asigna IDs a los rasgos nuevos y conserva los usos actuales de los existentes
+func prepareFeatures(features []models.Feature, existing []models.Feature) []models.Feature {
+	currentUses := make(map[string]int, len(existing))
+	for _, f := range existing {
+		if f.ID != "" && f.Uses != nil {
+			currentUses[f.ID] = f.Uses.Current
+		}
+	}
+
+	result := make([]models.Feature, 0, len(features))
+	for _, f := range features {
+		if f.ID == "" {
+			f.ID = generateID()
+		}
+
+		if f.Uses != nil {
+			uses := *f.Uses
+			if current, ok := currentUses[f.ID]; ok {
+				uses.Current = current
+			} else if uses.Current == 0 {
+				// Rasgo nuevo: empieza con todos los usos disponibles
+				uses.Current = uses.Max
+			}
+			if uses.Current > uses.Max {
+				uses.Current = uses.Max
+			}
+			f.Uses = &uses
+		}
+
+		result = append(result, f)
+	}
+
+	return result
+}
+
+// classHitDie - dado de golpe por clase del PHB (en minúsculas)
+var classHitDie = map[string]int{
+	"barbarian": 12, "bárbaro": 12,
+	"fighter": 10, "guerrero": 10, "paladin": 10, "paladín": 10, "ranger": 10, "explorador": 10,
+	"sorcerer": 6, "hechicero": 6, "wizard": 6, "mago": 6,
+	"bard": 8, "bardo": 8, "cleric": 8, "clérigo": 8, "druid": 8, "druida": 8,
+	"monk": 8, "monje": 8, "rogue": 8, "pícaro": 8, "warlock": 8, "brujo": 8,
+	"artificer": 8, "artífice": 8,
+}
+
+// resourceMax calcula el máximo de un recurso según su fórmula
+func resourceMax(resource models.Resource, level int, scores models.AbilityScores) int {
+	if resource.Formula == nil {
+		return resource.Max
+	}
+
+	f := resource.Formula
+	max := f.Base + f.PerLevel*level
+	if f.Ability != "" {
+		max += abilityModifier(abilityScore(scores, f.Ability))
+	}
+	if max < f.Minimum {
+		max = f.Minimum
+	}
+	if max < 0 {
+		max = 0
+	}
+	return max
+}
+
+// prepareResources asigna IDs, calcula máximos por fórmula y conserva el valor actual de los existentes.
+// Sin lista (nil) se conservan los recursos existentes.
+func prepareResources(resources []models.Resource, existing []models.Resource, level int, scores models.AbilityScores) []models.Resource {
+	if resources == nil {
+		resources = existing
+	}
+	current := make(map[string]int, len(existing))
+	for _, r := range existing {
+		if r.ID != "" {
+			current[r.ID] = r.Current
+		}
+	}
+
+	result := make([]models.Resource, 0, len(resources))
+	for _, r := range resources {
+		if r.ID == "" {
+			r.ID = generateID()
+		}
+		r.Max = resourceMax(r, level, scores)
+
+		if value, ok := current[r.ID]; ok {
+			r.Current = value
+		} else if r.Current == 0 {
+			// Recurso nuevo: empieza lleno
+			r.Current = r.Max
+		}
+		if r.Current > r.Max {
+			r.Current = r.Max
+		}
+
+		result = append(result, r)
+	}
+
+	return result
+}
+
+// prepareHitDice normaliza los dados de golpe; sin datos, los infiere de la clase y el nivel
+func prepareHitDice(pools []models.HitDicePool, existing []models.HitDicePool, class string, level int) []models.HitDicePool {
+	if len(pools) == 0 {
+		if len(existing) == 1 {
+			pools = []models.HitDicePool{{Die: existing[0].Die, Max: level}}
+		} else if len(existing) > 0 {
+			pools = existing
+		} else if die, ok := classHitDie[strings.ToLower(strings.TrimSpace(class))]; ok {
+			pools = []models.HitDicePool{{Die: die, Max: level}}
+		} else {
+			return []models.HitDicePool{}
+		}
+	}
+
+	current := make(map[int]int, len(existing))
+	for _, p := range existing {
+		current[p.Die] = p.Current
+	}
+
+	result := make([]models.HitDicePool, 0, len(pools))
+	for _, p := range pools {
+		if p.Max == 0 && len(pools) == 1 {
+			p.Max = level
+		}
+		if value, ok := current[p.Die]; ok {
+			p.Current = value
+		} else if p.Current == 0 {
+			p.Current = p.Max
+		}
+		if p.Current > p.Max {
+			p.Current = p.Max
+		}
+		result = append(result, p)
+	}
+
+	return result
+}
+
+// emptyIfNil evita guardar null en Firestore para listas vacías
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// abilityModifier calcula el modificador de una puntuación de característica
+func abilityModifier(score int) int {
+	if score < 0 {
+		score = 0
+	}
+	return score/2 - 5
+}
+
+// generateID genera un ID aleatorio para sub-elementos embebidos en un documento
+func generateID() string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
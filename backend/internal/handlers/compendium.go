@@ -0,0 +1,155 @@
+// backend/internal/handlers/compendium.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/compendium"
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// COMPENDIO DE ITEMS
+// ===========================
+
+var errUnknownSlug = errors.New("el slug no existe en el compendio")
+
+// GetCompendiumItems - Buscar en el compendio SRD (q, type, category, rarity, minCost, maxCost, limit, offset)
+func (h *Handler) GetCompendiumItems(c *gin.Context) {
+	filter, err := compendiumFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, compendium.Default().Search(filter))
+}
+
+// GetCompendiumItem - Detalle de un item del compendio
+func (h *Handler) GetCompendiumItem(c *gin.Context) {
+	item, ok := compendium.Default().Get(c.Param("slug"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": errUnknownSlug.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// ===========================
+// HELPERS DEL COMPENDIO
+// ===========================
+
+// compendiumFilterFromQuery arma el filtro de búsqueda a partir de los query params
+func compendiumFilterFromQuery(c *gin.Context) (compendium.Filter, error) {
+	filter := compendium.Filter{
+		Query:    c.Query("q"),
+		Type:     c.Query("type"),
+		Category: c.Query("category"),
+		Rarity:   c.Query("rarity"),
+	}
+
+	for param, target := range map[string]**float64{"minCost": &filter.MinCost, "maxCost": &filter.MaxCost} {
+		if raw := c.Query(param); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || value < 0 {
+				return filter, errors.New(param + " inválido")
+			}
+			*target = &value
+		}
+	}
+	for param, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if raw := c.Query(param); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 0 {
+				return filter, errors.New(param + " inválido")
+			}
+			*target = value
+		}
+	}
+	return filter, nil
+}
+
+// applyCompendium completa el request con los datos del compendio según Open5eSlug.
+// Si hay campaña se busca primero en su homebrew y después en el SRD. Lo enviado
+// explícitamente tiene prioridad; los datos de arma/armadura/contenedor/efecto
+// solo se copian si el tipo coincide. Un slug desconocido solo se acepta como
+// referencia externa si el request trae nombre y tipo.
+func (h *Handler) applyCompendium(ctx context.Context, campaignID string, req *models.CreateItemRequest) error {
+	if req.Open5eSlug == "" {
+		return nil
+	}
+
+	homebrew := []models.HomebrewEntry{}
+	if campaignID != "" {
+		var err error
+		if homebrew, err = h.campaignHomebrew(ctx, campaignID, models.HomebrewKindItem); err != nil {
+			return err
+		}
+	}
+	return applyCompendiumEntry(homebrew, req)
+}
+
+// applyCompendiumEntry es applyCompendium con el homebrew de la campaña ya cargado
+// (los lotes lo leen una sola vez)
+func applyCompendiumEntry(homebrew []models.HomebrewEntry, req *models.CreateItemRequest) error {
+	if req.Open5eSlug == "" {
+		return nil
+	}
+
+	entry, ok := compendium.Default().Get(req.Open5eSlug)
+	if item := homebrewItemBySlug(homebrew, req.Open5eSlug); item != nil {
+		entry, ok = *item, true
+	}
+	if !ok {
+		if req.Name == "" || req.Type == "" {
+			return errUnknownSlug
+		}
+		return nil
+	}
+
+	base := compendium.ToInventoryItem(entry)
+	req.Open5eSlug = entry.Slug
+	if req.Name == "" {
+		req.Name = base.Name
+	}
+	if req.Type == "" {
+		req.Type = string(base.Type)
+	}
+	if req.Description == "" {
+		req.Description = base.Description
+	}
+	if req.Value == 0 && req.Price == nil {
+		req.Value = base.Value
+	}
+	if req.Weight == 0 {
+		req.Weight = base.Weight
+	}
+	if req.Volume == 0 {
+		req.Volume = base.Volume
+	}
+	if req.MagicData == nil {
+		req.MagicData = base.MagicData
+	}
+
+	if models.ItemType(req.Type) != base.Type {
+		return nil
+	}
+	if req.WeaponData == nil {
+		req.WeaponData = base.WeaponData
+	}
+	if req.ArmorData == nil {
+		req.ArmorData = base.ArmorData
+	}
+	if req.ContainerData == nil {
+		req.ContainerData = base.ContainerData
+	}
+	if req.Effect == nil {
+		req.Effect = base.Effect
+	}
+	return nil
+}
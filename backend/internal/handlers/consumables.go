@@ -0,0 +1,217 @@
+// backend/internal/handlers/consumables.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// CONSUMIBLES
+// ===========================
+
+var (
+	errNotConsumable = errors.New("solo se pueden usar consumibles")
+	errNoEffect      = errors.New("el consumible no tiene un efecto definido")
+)
+
+// UseItem - Usar un consumible: tira su efecto, lo aplica al personaje (y a su
+// combatiente en el encuentro activo) y descuenta una unidad, todo en una transacción
+func (h *Handler) UseItem(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	itemRef := h.db.Collection("inventory_items").Doc(itemID)
+	var response models.UseItemResponse
+	var character models.Character
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		response = models.UseItemResponse{RemovedConditions: []string{}}
+
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errTransferItemNotFound
+		}
+		var item models.InventoryItem
+		if err := itemDoc.DataTo(&item); err != nil {
+			return err
+		}
+		if item.Type != models.ItemTypeConsumable {
+			return errNotConsumable
+		}
+		if item.Effect == nil {
+			return errNoEffect
+		}
+
+		character, err = h.itemOwnerInTx(tx, uid, item.CharacterID)
+		if err != nil {
+			return err
+		}
+
+		combatantRef, err := h.activeCombatantInTx(tx, character)
+		if err != nil {
+			return err
+		}
+
+		// Aplicar el efecto
+		effect := item.Effect
+		if effect.HealDice != "" {
+			expr, err := parseDice(effect.HealDice)
+			if err != nil {
+				return errNoEffect
+			}
+			roll := expr.roll(false)
+			response.Heal = &roll
+
+			newHP := minInt(character.CurrentHP+roll.Total, character.MaxHP)
+			response.Healed = maxInt(newHP-character.CurrentHP, 0)
+			if character.CurrentHP == 0 && newHP > 0 {
+				character.DeathSaves = models.DeathSaves{}
+			}
+			character.CurrentHP = maxInt(newHP, character.CurrentHP)
+		}
+		if effect.TemporaryHPDice != "" {
+			expr, err := parseDice(effect.TemporaryHPDice)
+			if err != nil {
+				return errNoEffect
+			}
+			roll := expr.roll(false)
+			response.TemporaryHP = &roll
+			// Los PG temporales no se acumulan: se queda el mayor
+			character.TemporaryHP = maxInt(character.TemporaryHP, roll.Total)
+		}
+		if len(effect.RemoveConditions) > 0 {
+			character.Conditions, response.RemovedConditions = removeConditions(character.Conditions, effect.RemoveConditions)
+		}
+		if character.Conditions == nil {
+			character.Conditions = []string{}
+		}
+
+		now := time.Now()
+		vitals := []firestore.Update{
+			{Path: "currentHp", Value: character.CurrentHP},
+			{Path: "temporaryHp", Value: character.TemporaryHP},
+			{Path: "conditions", Value: character.Conditions},
+			{Path: "deathSaves", Value: character.DeathSaves},
+		}
+
+		if err := tx.Update(h.db.Collection("characters").Doc(character.ID), append(vitals,
+			firestore.Update{Path: "updatedAt", Value: now})); err != nil {
+			return err
+		}
+		if combatantRef != nil {
+			if err := tx.Update(combatantRef, vitals); err != nil {
+				return err
+			}
+			response.CombatantID = combatantRef.ID
+		}
+
+		// Descontar o eliminar el item
+		if item.Quantity <= 1 {
+			return tx.Delete(itemRef)
+		}
+		item.Quantity--
+		item.UpdatedAt = now
+		response.Item = &item
+		return tx.Update(itemRef, []firestore.Update{
+			{Path: "quantity", Value: item.Quantity},
+			{Path: "updatedAt", Value: now},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errTransferItemNotFound), errors.Is(err, errVaultCharacterNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errTransferForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
+		case errors.Is(err, errNotConsumable), errors.Is(err, errNoEffect):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error usando item"})
+		}
+		return
+	}
+
+	response.Character = models.CharacterVitals{
+		ID:          character.ID,
+		CurrentHP:   character.CurrentHP,
+		MaxHP:       character.MaxHP,
+		TemporaryHP: character.TemporaryHP,
+		Conditions:  character.Conditions,
+	}
+
+	h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+	h.invalidateCharacterCache(ctx, character.ID)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ===========================
+// HELPERS DE CONSUMIBLES
+// ===========================
+
+// activeCombatantInTx busca el combatiente del personaje en el encuentro activo de su campaña (nil si no hay)
+func (h *Handler) activeCombatantInTx(tx *firestore.Transaction, character models.Character) (*firestore.DocumentRef, error) {
+	if character.CampaignID == "" {
+		return nil, nil
+	}
+
+	encounterDoc, err := tx.Documents(h.db.Collection("encounters").
+		Where("campaignId", "==", character.CampaignID).
+		Where("isActive", "==", true).
+		Limit(1)).Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	combatantDoc, err := tx.Documents(h.db.Collection("combatants").
+		Where("encounterId", "==", encounterDoc.Ref.ID).
+		Where("characterId", "==", character.ID).
+		Limit(1)).Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return combatantDoc.Ref, nil
+}
+
+// removeConditions quita las condiciones indicadas (sin distinguir mayúsculas) y devuelve las quitadas
+func removeConditions(current, remove []string) ([]string, []string) {
+	kept := []string{}
+	removed := []string{}
+	for _, condition := range current {
+		matched := false
+		for _, r := range remove {
+			if strings.EqualFold(strings.TrimSpace(r), condition) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			removed = append(removed, condition)
+		} else {
+			kept = append(kept, condition)
+		}
+	}
+	return kept, removed
+}
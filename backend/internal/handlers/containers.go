@@ -0,0 +1,343 @@
+// backend/internal/handlers/containers.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// CONTENEDORES
+// ===========================
+
+// maxContainerDepth limita el anidamiento al recorrer contenedores
+const maxContainerDepth = 20
+
+var (
+	errContainerNotFound    = errors.New("contenedor no encontrado")
+	errNotAContainer        = errors.New("el item no es un contenedor")
+	errContainerCycle       = errors.New("un contenedor no puede guardarse dentro de sí mismo")
+	errContainerWeightLimit = errors.New("el contenedor no admite tanto peso")
+	errContainerVolumeLimit = errors.New("el contenedor no tiene espacio suficiente")
+	errContainerNotEmpty    = errors.New("vacía el contenedor antes de moverlo a otro personaje")
+	errContainerHasContents = errors.New("vacía el contenedor antes de quitarle su capacidad")
+)
+
+// MoveItem - Guardar un item en un contenedor del mismo personaje o sacarlo (containerId "")
+func (h *Handler) MoveItem(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	var req models.MoveItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itemRef := h.db.Collection("inventory_items").Doc(itemID)
+	var moved models.InventoryItem
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errTransferItemNotFound
+		}
+		if err := itemDoc.DataTo(&moved); err != nil {
+			return err
+		}
+
+		if _, err := h.itemOwnerInTx(tx, uid, moved.CharacterID); err != nil {
+			return err
+		}
+
+		if req.ContainerID != "" {
+			items, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", moved.CharacterID))
+			if err != nil {
+				return err
+			}
+			if err := validateContainerPlacement(items, moved, req.ContainerID); err != nil {
+				return err
+			}
+		}
+
+		moved.ContainerID = req.ContainerID
+		moved.UpdatedAt = time.Now()
+		return tx.Update(itemRef, []firestore.Update{
+			{Path: "containerId", Value: req.ContainerID},
+			{Path: "updatedAt", Value: moved.UpdatedAt},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errTransferItemNotFound), errors.Is(err, errVaultCharacterNotFound), errors.Is(err, errContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errTransferForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
+		case errors.Is(err, errNotAContainer), errors.Is(err, errContainerCycle),
+			errors.Is(err, errContainerWeightLimit), errors.Is(err, errContainerVolumeLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error moviendo item"})
+		}
+		return
+	}
+
+	h.invalidateCharacterCache(ctx, moved.CharacterID)
+
+	c.JSON(http.StatusOK, moved)
+}
+
+// releaseContainerContents deja el contenido de un contenedor eliminado en el contenedor padre (o suelto)
+func (h *Handler) releaseContainerContents(ctx context.Context, container models.InventoryItem) {
+	if container.ContainerData == nil {
+		return
+	}
+
+	iter := h.db.Collection("inventory_items").
+		Where("characterId", "==", container.CharacterID).
+		Where("containerId", "==", container.ID).
+		Documents(ctx)
+
+	batch := h.db.Batch()
+	count := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Error iterando contenido del contenedor: %v", err)
+			break
+		}
+		batch.Update(doc.Ref, []firestore.Update{
+			{Path: "containerId", Value: container.ContainerID},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+		count++
+	}
+
+	if count > 0 {
+		if _, err := batch.Commit(ctx); err != nil {
+			log.Printf("Error liberando contenido del contenedor: %v", err)
+		}
+	}
+}
+
+// ===========================
+// HELPERS DE CONTENEDORES
+// ===========================
+
+// containerIsEmpty falla si el item es un contenedor con items dentro
+func containerIsEmpty(tx *firestore.Transaction, db *firestore.Client, item models.InventoryItem) error {
+	if item.ContainerData == nil {
+		return nil
+	}
+	_, err := tx.Documents(db.Collection("inventory_items").
+		Where("characterId", "==", item.CharacterID).
+		Where("containerId", "==", item.ID).
+		Limit(1)).Next()
+	if err == iterator.Done {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errContainerNotEmpty
+}
+
+// itemsByContainer agrupa los items por containerId
+func itemsByContainer(items []models.InventoryItem) map[string][]models.InventoryItem {
+	grouped := make(map[string][]models.InventoryItem)
+	for _, item := range items {
+		grouped[item.ContainerID] = append(grouped[item.ContainerID], item)
+	}
+	return grouped
+}
+
+// effectiveWeight - peso de un item incluyendo su contenido, salvo en contenedores mágicos
+func effectiveWeight(grouped map[string][]models.InventoryItem, item models.InventoryItem, depth int) float64 {
+	weight := item.Weight * float64(item.Quantity)
+	if item.ContainerData == nil || item.ContainerData.IgnoreContentsWeight || depth > maxContainerDepth {
+		return weight
+	}
+	for _, child := range grouped[item.ID] {
+		weight += effectiveWeight(grouped, child, depth+1)
+	}
+	return weight
+}
+
+// containerLoad - peso y volumen de los items guardados directamente en el contenedor
+func containerLoad(grouped map[string][]models.InventoryItem, containerID, excludeID string) (float64, float64) {
+	weight, volume := 0.0, 0.0
+	for _, child := range grouped[containerID] {
+		if child.ID == excludeID {
+			continue
+		}
+		weight += effectiveWeight(grouped, child, 0)
+		volume += child.Volume * float64(child.Quantity)
+	}
+	return weight, volume
+}
+
+// validateContainerPlacement comprueba que item pueda guardarse en containerID:
+// mismo personaje, es un contenedor, no hay ciclos y hay capacidad.
+func validateContainerPlacement(items []models.InventoryItem, item models.InventoryItem, containerID string) error {
+	byID := make(map[string]models.InventoryItem, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+
+	container, ok := byID[containerID]
+	if !ok {
+		return errContainerNotFound
+	}
+	if container.ContainerData == nil {
+		return errNotAContainer
+	}
+
+	// El contenedor destino no puede ser el item ni estar dentro de él
+	for current, depth := containerID, 0; current != "" && depth <= maxContainerDepth; depth++ {
+		if current == item.ID {
+			return errContainerCycle
+		}
+		current = byID[current].ContainerID
+	}
+
+	grouped := itemsByContainer(items)
+	weight, volume := containerLoad(grouped, containerID, item.ID)
+	data := container.ContainerData
+
+	if data.CapacityWeight > 0 && weight+effectiveWeight(grouped, item, 0) > data.CapacityWeight {
+		return errContainerWeightLimit
+	}
+	if data.CapacityVolume > 0 && volume+item.Volume*float64(item.Quantity) > data.CapacityVolume {
+		return errContainerVolumeLimit
+	}
+
+	return nil
+}
+
+// validateContainerContents comprueba que lo que ya guarda el item siga cabiendo tras editarlo
+func validateContainerContents(items []models.InventoryItem, container models.InventoryItem) error {
+	grouped := itemsByContainer(items)
+	if len(grouped[container.ID]) == 0 {
+		return nil
+	}
+	if container.ContainerData == nil {
+		return errContainerHasContents
+	}
+
+	weight, volume := containerLoad(grouped, container.ID, "")
+	data := container.ContainerData
+	if data.CapacityWeight > 0 && weight > data.CapacityWeight {
+		return errContainerWeightLimit
+	}
+	if data.CapacityVolume > 0 && volume > data.CapacityVolume {
+		return errContainerVolumeLimit
+	}
+	return nil
+}
+
+// carriedWeight - peso que carga el personaje: excluye lo que va en monturas y
+// el contenido de contenedores mágicos
+func carriedWeight(items []models.InventoryItem) float64 {
+	byID := make(map[string]models.InventoryItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	total := 0.0
+	for _, item := range items {
+		if item.ContainerData != nil && item.ContainerData.NotCarried {
+			continue
+		}
+
+		counts := true
+		for current, depth := item.ContainerID, 0; current != "" && depth <= maxContainerDepth; depth++ {
+			parent, ok := byID[current]
+			if !ok {
+				break
+			}
+			if parent.ContainerData != nil && (parent.ContainerData.NotCarried || parent.ContainerData.IgnoreContentsWeight) {
+				counts = false
+				break
+			}
+			current = parent.ContainerID
+		}
+
+		if counts {
+			total += item.Weight * float64(item.Quantity)
+		}
+	}
+	return total
+}
+
+// buildInventoryTree arma el árbol de items; los huérfanos (contenedor inexistente) quedan en la raíz
+func buildInventoryTree(items []models.InventoryItem) []models.InventoryNode {
+	exists := make(map[string]bool, len(items))
+	for _, item := range items {
+		exists[item.ID] = true
+	}
+
+	grouped := make(map[string][]models.InventoryItem)
+	for _, item := range items {
+		parent := item.ContainerID
+		if !exists[parent] || parent == item.ID {
+			parent = ""
+		}
+		grouped[parent] = append(grouped[parent], item)
+	}
+
+	var build func(parentID string, depth int) []models.InventoryNode
+	build = func(parentID string, depth int) []models.InventoryNode {
+		nodes := []models.InventoryNode{}
+		if depth > maxContainerDepth {
+			return nodes
+		}
+		for _, item := range grouped[parentID] {
+			node := models.InventoryNode{
+				Item:     item,
+				Contents: build(item.ID, depth+1),
+			}
+			node.ContentsWeight, node.ContentsVolume = containerLoad(grouped, item.ID, "")
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build("", 0)
+}
+
+// reassignItemIDs genera nuevos documentos para items copiados (clonar/importar)
+// y actualiza las referencias a contenedores con los nuevos IDs.
+func reassignItemIDs(items []models.InventoryItem, collection *firestore.CollectionRef) []*firestore.DocumentRef {
+	refs := make([]*firestore.DocumentRef, len(items))
+	newIDs := make(map[string]string, len(items))
+	for i := range items {
+		refs[i] = collection.NewDoc()
+		if items[i].ID != "" {
+			newIDs[items[i].ID] = refs[i].ID
+		}
+	}
+
+	for i := range items {
+		items[i].ID = refs[i].ID
+		items[i].ContainerID = newIDs[items[i].ContainerID]
+	}
+	return refs
+}
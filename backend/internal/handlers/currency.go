@@ -0,0 +1,352 @@
+// backend/internal/handlers/currency.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// TRANSACCIONES DE MONEDAS
+// ===========================
+
+const MAX_CURRENCY = 999999999
+
+var (
+	errInvalidAmount     = errors.New("cantidad inválida: usa el formato \"+5 gp\" o \"-120 sp\" (cp, sp, ep, gp, pp)")
+	errInsufficientFunds = errors.New("fondos insuficientes")
+	errNegativeCurrency  = errors.New("la moneda no puede ser negativa")
+	errCurrencyOverflow  = fmt.Errorf("el máximo de monedas por tipo es %d", MAX_CURRENCY)
+)
+
+// denominations - valor en cobre de cada moneda, de menor a mayor
+var denominations = []struct {
+	code  string
+	value int
+}{
+	{"cp", 1}, {"sp", 10}, {"ep", 50}, {"gp", 100}, {"pp", 1000},
+}
+
+var amountPattern = regexp.MustCompile(`^([+-]?)\s*(\d{1,9})\s*(cp|sp|ep|gp|pp)$`)
+
+// CreateCurrencyTransaction - Aplicar un movimiento relativo ("+5 gp", "-120 sp") con su motivo
+func (h *Handler) CreateCurrencyTransaction(c *gin.Context) {
+	uid := c.GetString("uid")
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.CurrencyTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, count, err := parseAmount(req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var entry models.CurrencyLedgerEntry
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		charDoc, err := tx.Get(h.db.Collection("characters").Doc(charID))
+		if err != nil {
+			return errVaultCharacterNotFound
+		}
+		var character models.Character
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+
+		currencyRef := h.db.Collection("currencies").Doc(charID)
+		balance, err := currencyInTx(tx, currencyRef)
+		if err != nil {
+			return err
+		}
+
+		var updated models.Currency
+		if count >= 0 {
+			updated = setDenomination(balance, code, getDenomination(balance, code)+count)
+		} else if updated, err = payWithChange(balance, code, -count); err != nil {
+			return err
+		}
+		if err := validateCurrency(updated); err != nil {
+			return err
+		}
+
+		if err := tx.Set(currencyRef, updated); err != nil {
+			return err
+		}
+
+		entry, err = h.ledgerEntryInTx(tx, &character, uid, req.Reason, req.Amount, balance, updated)
+		return err
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errVaultCharacterNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errInsufficientFunds), errors.Is(err, errCurrencyOverflow), errors.Is(err, errNegativeCurrency):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error aplicando transacción"})
+		}
+		return
+	}
+
+	h.invalidateCharacterCache(ctx, charID)
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetCurrencyLedger - Historial de movimientos de monedas del personaje
+func (h *Handler) GetCurrencyLedger(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	iter := h.db.Collection("currency_ledger").
+		Where("characterId", "==", charID).
+		OrderBy("createdAt", firestore.Desc).
+		Limit(100).
+		Documents(ctx)
+
+	var entries []models.CurrencyLedgerEntry
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo movimientos"})
+			return
+		}
+
+		var entry models.CurrencyLedgerEntry
+		if err := doc.DataTo(&entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if entries == nil {
+		entries = []models.CurrencyLedgerEntry{}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ===========================
+// HELPERS DE MONEDAS
+// ===========================
+
+// ledgerEntryInTx agrega al libro el movimiento before -> after del personaje
+func (h *Handler) ledgerEntryInTx(tx *firestore.Transaction, char *models.Character, uid, reason, amount string, before, after models.Currency) (models.CurrencyLedgerEntry, error) {
+	ref := h.db.Collection("currency_ledger").NewDoc()
+	entry := models.CurrencyLedgerEntry{
+		ID:          ref.ID,
+		CharacterID: char.ID,
+		CampaignID:  char.CampaignID,
+		PerformedBy: uid,
+		Reason:      reason,
+		Amount:      amount,
+		Delta:       addCurrency(after, before, -1),
+		Balance:     after,
+		CreatedAt:   time.Now(),
+	}
+	return entry, tx.Set(ref, entry)
+}
+
+// parseAmount interpreta "+5 gp" / "-120 sp" / "30cp"
+func parseAmount(amount string) (string, int, error) {
+	match := amountPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(amount)))
+	if match == nil {
+		return "", 0, errInvalidAmount
+	}
+	count, err := strconv.Atoi(match[2])
+	if err != nil || count == 0 {
+		return "", 0, errInvalidAmount
+	}
+	if match[1] == "-" {
+		count = -count
+	}
+	return match[3], count, nil
+}
+
+func getDenomination(currency models.Currency, code string) int {
+	switch code {
+	case "cp":
+		return currency.Copper
+	case "sp":
+		return currency.Silver
+	case "ep":
+		return currency.Electrum
+	case "gp":
+		return currency.Gold
+	case "pp":
+		return currency.Platinum
+	}
+	return 0
+}
+
+func setDenomination(currency models.Currency, code string, value int) models.Currency {
+	switch code {
+	case "cp":
+		currency.Copper = value
+	case "sp":
+		currency.Silver = value
+	case "ep":
+		currency.Electrum = value
+	case "gp":
+		currency.Gold = value
+	case "pp":
+		currency.Platinum = value
+	}
+	return currency
+}
+
+// currencyValueCP - valor total en piezas de cobre
+func currencyValueCP(currency models.Currency) int {
+	total := 0
+	for _, d := range denominations {
+		total += getDenomination(currency, d.code) * d.value
+	}
+	return total
+}
+
+// payWithChange paga count monedas de code. Usa primero esa moneda, luego las
+// menores a mayores y, si hace falta, rompe una moneda más grande y devuelve
+// el cambio en po, pp y pc.
+func payWithChange(balance models.Currency, code string, count int) (models.Currency, error) {
+	value := 0
+	for _, d := range denominations {
+		if d.code == code {
+			value = d.value
+		}
+	}
+
+	remaining := count * value
+	if currencyValueCP(balance) < remaining {
+		return balance, errInsufficientFunds
+	}
+
+	// 1. La moneda pedida
+	use := minInt(getDenomination(balance, code), count)
+	balance = setDenomination(balance, code, getDenomination(balance, code)-use)
+	remaining -= use * value
+
+	// 2. Monedas enteras, de menor a mayor
+	for _, d := range denominations {
+		if remaining == 0 {
+			break
+		}
+		use := minInt(getDenomination(balance, d.code), remaining/d.value)
+		balance = setDenomination(balance, d.code, getDenomination(balance, d.code)-use)
+		remaining -= use * d.value
+	}
+
+	// 3. Romper la moneda más chica que alcance y devolver el cambio
+	if remaining > 0 {
+		for _, d := range denominations {
+			if getDenomination(balance, d.code) > 0 && d.value > remaining {
+				balance = setDenomination(balance, d.code, getDenomination(balance, d.code)-1)
+				balance = addCurrency(balance, currencyFromCP(d.value-remaining), 1)
+				remaining = 0
+				break
+			}
+		}
+	}
+
+	if remaining > 0 {
+		return balance, errInsufficientFunds
+	}
+	return balance, nil
+}
+
+// currencyFromCP expresa una cantidad de cobre en po, pp y pc
+func currencyFromCP(cp int) models.Currency {
+	return models.Currency{
+		Gold:   cp / 100,
+		Silver: (cp % 100) / 10,
+		Copper: cp % 10,
+	}
+}
+
+func validateCurrency(currency models.Currency) error {
+	for _, d := range denominations {
+		value := getDenomination(currency, d.code)
+		if value < 0 {
+			return errNegativeCurrency
+		}
+		if value > MAX_CURRENCY {
+			return errCurrencyOverflow
+		}
+	}
+	return nil
+}
+
+// ===========================
+// PRECIOS Y VALOR EN COBRE
+// ===========================
+
+// denominationValue - valor en pc de una moneda (0 si el código no existe)
+func denominationValue(code string) int64 {
+	for _, d := range denominations {
+		if d.code == code {
+			return int64(d.value)
+		}
+	}
+	return 0
+}
+
+// gpToCP convierte un precio en po a pc redondeando al cobre
+func gpToCP(gp float64) int64 {
+	return int64(math.Round(gp * 100))
+}
+
+// itemPriceCP - precio por unidad en pc (los items anteriores solo tienen value en po)
+func itemPriceCP(item models.InventoryItem) int64 {
+	if item.PriceCP > 0 {
+		return item.PriceCP
+	}
+	return gpToCP(item.Value)
+}
+
+// priceDenomination - la moneda más grande entre po, pp y pc que expresa el precio sin fracciones
+func priceDenomination(cp int64) string {
+	switch {
+	case cp%100 == 0:
+		return "gp"
+	case cp%10 == 0:
+		return "sp"
+	}
+	return "cp"
+}
+
+// newWorth arma el valor normalizado a partir de un total en pc
+func newWorth(cp int64) models.Worth {
+	return models.Worth{TotalCP: cp, Breakdown: currencyFromCP(int(cp))}
+}
+
+// inventoryWorth suma en pc el valor de los items, el de las monedas y el total
+func inventoryWorth(items []models.InventoryItem, currency models.Currency) (itemsWorth, coinsWorth, netWorth models.Worth) {
+	var itemsCP int64
+	for _, item := range items {
+		itemsCP += itemPriceCP(item) * int64(item.Quantity)
+	}
+	coinsCP := int64(currencyValueCP(currency))
+	return newWorth(itemsCP), newWorth(coinsCP), newWorth(itemsCP + coinsCP)
+}
@@ -0,0 +1,180 @@
+// backend/internal/handlers/derived.go
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// ESTADÍSTICAS DERIVADAS
+// ===========================
+
+// GetCharacterStats - Estadísticas derivadas (modificadores, skills, carga y velocidad)
+func (h *Handler) GetCharacterStats(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	character, items, currency, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, computeDerivedStats(character, items, currency))
+}
+
+// abilityOrder - orden canónico de las características en la hoja
+var abilityOrder = []string{"strength", "dexterity", "constitution", "intelligence", "wisdom", "charisma"}
+
+// abilityScore devuelve la puntuación por nombre completo o abreviatura ("dex")
+func abilityScore(scores models.AbilityScores, ability string) int {
+	switch strings.ToLower(ability) {
+	case "strength", "str":
+		return scores.Strength
+	case "dexterity", "dex":
+		return scores.Dexterity
+	case "constitution", "con":
+		return scores.Constitution
+	case "intelligence", "int":
+		return scores.Intelligence
+	case "wisdom", "wis":
+		return scores.Wisdom
+	case "charisma", "cha":
+		return scores.Charisma
+	}
+	return 10
+}
+
+// hasSaveProficiency indica si el personaje es competente en la salvación
+func hasSaveProficiency(saves models.SavingThrows, ability string) bool {
+	switch ability {
+	case "strength":
+		return saves.Strength
+	case "dexterity":
+		return saves.Dexterity
+	case "constitution":
+		return saves.Constitution
+	case "intelligence":
+		return saves.Intelligence
+	case "wisdom":
+		return saves.Wisdom
+	case "charisma":
+		return saves.Charisma
+	}
+	return false
+}
+
+// skillBonus calcula el bonificador total de una skill
+func skillBonus(char *models.Character, skill models.Skill) int {
+	bonus := abilityModifier(abilityScore(char.AbilityScores, skill.Ability))
+	if skill.Expertise {
+		bonus += char.ProficiencyBonus * 2
+	} else if skill.Proficient {
+		bonus += char.ProficiencyBonus
+	}
+	return bonus
+}
+
+// computeDerivedStats calcula modificadores, salvaciones, skills y valor del inventario
+func computeDerivedStats(char *models.Character, items []models.InventoryItem, currency models.Currency) models.DerivedStats {
+	derived := models.DerivedStats{
+		AbilityModifiers: make(map[string]int, len(abilityOrder)),
+		SavingThrows:     make(map[string]int, len(abilityOrder)),
+		Skills:           make(map[string]int, len(char.Skills)),
+	}
+
+	for _, ability := range abilityOrder {
+		mod := abilityModifier(abilityScore(char.AbilityScores, ability))
+		derived.AbilityModifiers[ability] = mod
+
+		save := mod
+		if hasSaveProficiency(char.SavingThrows, ability) {
+			save += char.ProficiencyBonus
+		}
+		derived.SavingThrows[ability] = save
+	}
+
+	perception := 0
+	hasPerception := false
+	for _, skill := range char.Skills {
+		bonus := skillBonus(char, skill)
+		derived.Skills[skill.Name] = bonus
+		if strings.EqualFold(skill.Name, "perception") {
+			perception = bonus
+			hasPerception = true
+		}
+	}
+	if !hasPerception {
+		perception = derived.AbilityModifiers["wisdom"]
+	}
+	derived.PassivePerception = 10 + perception
+
+	_, _, derived.NetWorth = inventoryWorth(items, currency)
+	derived.InventoryValue = float64(derived.NetWorth.TotalCP) / 100
+
+	derived.Encumbrance = computeEncumbrance(char.AbilityScores, items, currency)
+	derived.Speed = effectiveSpeed(char, items, derived.Encumbrance)
+
+	return derived
+}
+
+// currencyWeight - 50 monedas pesan 1 libra
+func currencyWeight(currency models.Currency) float64 {
+	coins := currency.Copper + currency.Silver + currency.Electrum + currency.Gold + currency.Platinum
+	return float64(coins) / 50.0
+}
+
+// computeEncumbrance calcula el peso cargado (sin monturas ni contenido de contenedores
+// mágicos) y el estado según la regla variante:
+// más de FUE x5 estorbado (-10 pies), más de FUE x10 muy estorbado (-20 pies),
+// más de FUE x15 supera la capacidad de carga (velocidad 5 pies).
+func computeEncumbrance(scores models.AbilityScores, items []models.InventoryItem, currency models.Currency) models.Encumbrance {
+	strength := float64(scores.Strength)
+	enc := models.Encumbrance{
+		CurrencyWeight:      currencyWeight(currency),
+		CarryingCapacity:    strength * 15,
+		EncumberedAt:        strength * 5,
+		HeavilyEncumberedAt: strength * 10,
+		Status:              models.EncumbranceNone,
+	}
+
+	enc.CarriedWeight = math.Round((carriedWeight(items)+enc.CurrencyWeight)*100) / 100
+
+	switch {
+	case enc.CarriedWeight > enc.CarryingCapacity:
+		enc.Status = models.EncumbranceOverCapacity
+	case enc.CarriedWeight > enc.HeavilyEncumberedAt:
+		enc.Status = models.EncumbranceHeavy
+		enc.SpeedPenalty = 20
+	case enc.CarriedWeight > enc.EncumberedAt:
+		enc.Status = models.EncumbranceEncumbered
+		enc.SpeedPenalty = 10
+	}
+
+	return enc
+}
+
+// effectiveSpeed aplica la carga y el requisito de Fuerza de la armadura equipada
+func effectiveSpeed(char *models.Character, items []models.InventoryItem, enc models.Encumbrance) int {
+	if enc.Status == models.EncumbranceOverCapacity {
+		return minInt(char.Speed, 5)
+	}
+
+	speed := char.Speed - enc.SpeedPenalty
+	for _, item := range items {
+		if item.Equipped && item.ArmorData != nil && !isShield(item) &&
+			char.AbilityScores.Strength < item.ArmorData.StrengthRequirement {
+			speed -= 10
+			break
+		}
+	}
+
+	return maxInt(speed, 0)
+}
@@ -0,0 +1,156 @@
+// backend/internal/handlers/dice.go
+package handlers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// DADOS
+// ===========================
+
+// diceTerm es un grupo de dados iguales ("2d6"); sign es 1 o -1
+type diceTerm struct {
+	count int
+	sides int
+	sign  int
+}
+
+// diceExpr es una expresión como "1d8+2d6+3"
+type diceExpr struct {
+	terms    []diceTerm
+	modifier int
+}
+
+var diceTermPattern = regexp.MustCompile(`^(\d*)d(\d+)$`)
+
+// parseDice interpreta expresiones de dados: "1d8", "2d6+3", "1d8+1d6-1"
+func parseDice(expression string) (diceExpr, error) {
+	var expr diceExpr
+	clean := strings.ToLower(strings.ReplaceAll(expression, " ", ""))
+	if clean == "" {
+		return expr, fmt.Errorf("expresión de dados vacía")
+	}
+
+	clean = strings.ReplaceAll(clean, "-", "+-")
+	for _, part := range strings.Split(clean, "+") {
+		if part == "" {
+			continue
+		}
+
+		sign := 1
+		if strings.HasPrefix(part, "-") {
+			sign = -1
+			part = part[1:]
+		}
+
+		if match := diceTermPattern.FindStringSubmatch(part); match != nil {
+			count := 1
+			if match[1] != "" {
+				count, _ = strconv.Atoi(match[1])
+			}
+			sides, _ := strconv.Atoi(match[2])
+			if count < 1 || count > 100 || sides < 1 || sides > 1000 {
+				return expr, fmt.Errorf("dados fuera de rango: %s", part)
+			}
+			expr.terms = append(expr.terms, diceTerm{count: count, sides: sides, sign: sign})
+			continue
+		}
+
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return expr, fmt.Errorf("expresión de dados inválida: %s", expression)
+		}
+		expr.modifier += sign * value
+	}
+
+	return expr, nil
+}
+
+// withModifier devuelve la expresión con un modificador adicional
+func (d diceExpr) withModifier(bonus int) diceExpr {
+	d.modifier += bonus
+	return d
+}
+
+// String formatea la expresión ("1d8+3")
+func (d diceExpr) String() string {
+	var b strings.Builder
+	for i, t := range d.terms {
+		if t.sign < 0 {
+			b.WriteString("-")
+		} else if i > 0 {
+			b.WriteString("+")
+		}
+		fmt.Fprintf(&b, "%dd%d", t.count, t.sides)
+	}
+	if d.modifier != 0 || len(d.terms) == 0 {
+		if d.modifier >= 0 && len(d.terms) > 0 {
+			b.WriteString("+")
+		}
+		fmt.Fprintf(&b, "%d", d.modifier)
+	}
+	return b.String()
+}
+
+// roll tira la expresión; en crítico se duplica la cantidad de dados
+func (d diceExpr) roll(critical bool) models.DiceRoll {
+	result := models.DiceRoll{
+		Expression: d.String(),
+		Rolls:      []int{},
+		Modifier:   d.modifier,
+	}
+
+	total := d.modifier
+	for _, t := range d.terms {
+		count := t.count
+		if critical {
+			count *= 2
+		}
+		for i := 0; i < count; i++ {
+			roll := rollDie(t.sides)
+			result.Rolls = append(result.Rolls, roll)
+			total += t.sign * roll
+		}
+	}
+
+	if total < 0 {
+		total = 0
+	}
+	result.Total = total
+	return result
+}
+
+// rollD20 tira un d20 normal, con ventaja o con desventaja; devuelve las tiradas y el resultado natural
+func rollD20(mode string) ([]int, int) {
+	first := rollDie(20)
+	if mode != "advantage" && mode != "disadvantage" {
+		return []int{first}, first
+	}
+
+	second := rollDie(20)
+	natural := first
+	if (mode == "advantage" && second > first) || (mode == "disadvantage" && second < first) {
+		natural = second
+	}
+	return []int{first, second}, natural
+}
+
+// rollDie tira un dado de n caras
+func rollDie(sides int) int {
+	if sides < 1 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(sides)))
+	if err != nil {
+		return 1
+	}
+	return int(n.Int64()) + 1
+}
@@ -0,0 +1,396 @@
+// backend/internal/handlers/equipment.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// EQUIPO Y SINTONÍA
+// ===========================
+
+const maxAttunedItems = 3
+
+var (
+	errEquipItemNotFound     = errors.New("item no encontrado")
+	errArmorAlreadyEquipped  = errors.New("ya hay una armadura equipada")
+	errShieldAlreadyEquipped = errors.New("ya hay un escudo equipado")
+	errAttunementLimit       = fmt.Errorf("no se pueden sintonizar más de %d objetos", maxAttunedItems)
+)
+
+// GetCharacterEquipment - Equipo, objetos sintonizados y CA derivada
+func (h *Handler) GetCharacterEquipment(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	character, items, _, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+
+	summary := models.EquipmentSummary{
+		ArmorClass: deriveArmorClass(character, items),
+		Equipped:   []models.InventoryItem{},
+		Attuned:    []models.InventoryItem{},
+		Warnings:   armorWarnings(character, items),
+	}
+	for _, item := range items {
+		if item.Equipped {
+			summary.Equipped = append(summary.Equipped, item)
+		}
+		if item.Attuned {
+			summary.Attuned = append(summary.Attuned, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// EquipItem - Equipar un item (una armadura y un escudo como máximo)
+func (h *Handler) EquipItem(c *gin.Context) {
+	h.setItemState(c, "equipped", true)
+}
+
+// UnequipItem - Desequipar un item
+func (h *Handler) UnequipItem(c *gin.Context) {
+	h.setItemState(c, "equipped", false)
+}
+
+// AttuneItem - Sintonizar un objeto mágico (máximo 3)
+func (h *Handler) AttuneItem(c *gin.Context) {
+	h.setItemState(c, "attuned", true)
+}
+
+// UnattuneItem - Romper la sintonía con un objeto
+func (h *Handler) UnattuneItem(c *gin.Context) {
+	h.setItemState(c, "attuned", false)
+}
+
+// setItemState cambia equipped/attuned validando los límites y recalcula la CA del personaje
+func (h *Handler) setItemState(c *gin.Context, field string, value bool) {
+	uid := c.GetString("uid")
+	charID := c.Param("charId")
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var response models.EquipmentResponse
+	var character models.Character
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+
+		items, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", charID))
+		if err != nil {
+			return err
+		}
+
+		index := -1
+		for i, item := range items {
+			if item.ID == itemID {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return errEquipItemNotFound
+		}
+
+		target := &items[index]
+		if value {
+			if field == "attuned" && !target.Attuned {
+				if err := checkAttunement(&character, *target); err != nil {
+					return err
+				}
+			}
+			if err := checkEquipLimits(items, *target, field); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		switch field {
+		case "equipped":
+			target.Equipped = value
+		case "attuned":
+			target.Attuned = value
+		}
+		target.UpdatedAt = now
+
+		response.Item = *target
+		response.ArmorClass = character.ArmorClass
+		response.Warnings = armorWarnings(&character, items)
+
+		if err := tx.Update(h.db.Collection("inventory_items").Doc(itemID), []firestore.Update{
+			{Path: field, Value: value},
+			{Path: "updatedAt", Value: now},
+		}); err != nil {
+			return err
+		}
+
+		// Solo armaduras y escudos cambian la CA: con el resto se conserva la CA de la
+		// hoja, que puede incluir bonificadores cargados a mano (armadura natural, anillos...)
+		if target.ArmorData == nil {
+			return nil
+		}
+		response.ArmorClass = deriveArmorClass(&character, items)
+		updates := []firestore.Update{
+			{Path: "armorClass", Value: response.ArmorClass},
+			{Path: "updatedAt", Value: now},
+		}
+		if err := tx.Update(charRef, updates); err != nil {
+			return err
+		}
+		after := characterAfterUpdates(&character, updates)
+		return h.recordCharacterVersionInTx(tx, &character, &after, uid, models.CharacterVersionEquipment)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errEquipItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errArmorAlreadyEquipped), errors.Is(err, errShieldAlreadyEquipped), errors.Is(err, errAttunementLimit),
+			errors.Is(err, errAttunementNotRequired), errors.Is(err, errAttunementNotPermitted):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando equipo"})
+		}
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+	h.invalidateCharacterCache(ctx, charID)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// refreshArmorClass recalcula y guarda la CA del personaje junto con su versión en el
+// historial (best-effort). Solo se usa al quitar una armadura o escudo equipados;
+// uid es quien hizo el cambio de equipo.
+func (h *Handler) refreshArmorClass(ctx context.Context, charID, uid string) {
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+	changed := false
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		changed = false
+
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+		items, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", charID))
+		if err != nil {
+			return err
+		}
+
+		ac := deriveArmorClass(&character, items)
+		if ac == character.ArmorClass {
+			return nil
+		}
+
+		updates := []firestore.Update{
+			{Path: "armorClass", Value: ac},
+			{Path: "updatedAt", Value: time.Now()},
+		}
+		if err := tx.Update(charRef, updates); err != nil {
+			return err
+		}
+		changed = true
+		after := characterAfterUpdates(&character, updates)
+		return h.recordCharacterVersionInTx(tx, &character, &after, uid, models.CharacterVersionEquipment)
+	})
+	if err != nil {
+		log.Printf("⚠️ Error recalculando CA de %s: %v", charID, err)
+		return
+	}
+
+	if changed {
+		h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+	}
+}
+
+// ===========================
+// HELPERS DE EQUIPO
+// ===========================
+
+// characterItemsInTx lee los items de un personaje dentro de una transacción
+func characterItemsInTx(tx *firestore.Transaction, query firestore.Query) ([]models.InventoryItem, error) {
+	iter := tx.Documents(query)
+	items := []models.InventoryItem{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var item models.InventoryItem
+		if err := doc.DataTo(&item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// checkEquipLimits valida una armadura, un escudo y 3 objetos sintonizados
+func checkEquipLimits(items []models.InventoryItem, target models.InventoryItem, field string) error {
+	if field == "attuned" {
+		if target.Attuned {
+			return nil
+		}
+		attuned := 0
+		for _, item := range items {
+			if item.Attuned {
+				attuned++
+			}
+		}
+		if attuned >= maxAttunedItems {
+			return errAttunementLimit
+		}
+		return nil
+	}
+
+	if target.ArmorData == nil {
+		return nil
+	}
+	for _, other := range items {
+		if other.ID == target.ID || !other.Equipped || other.ArmorData == nil {
+			continue
+		}
+		if isShield(other) && isShield(target) {
+			return errShieldAlreadyEquipped
+		}
+		if !isShield(other) && !isShield(target) {
+			return errArmorAlreadyEquipped
+		}
+	}
+	return nil
+}
+
+func isShield(item models.InventoryItem) bool {
+	return item.Type == models.ItemTypeShield ||
+		(item.ArmorData != nil && strings.EqualFold(item.ArmorData.ArmorType, "Shield"))
+}
+
+// armorCategory devuelve "light", "medium", "heavy" o "shield" (como en ArmorProficiencies)
+func armorCategory(item models.InventoryItem) string {
+	if isShield(item) {
+		return "shield"
+	}
+	armorType := strings.ToLower(item.ArmorData.ArmorType)
+	for _, category := range []string{"light", "medium", "heavy"} {
+		if strings.HasPrefix(armorType, category) {
+			return category
+		}
+	}
+	return armorType
+}
+
+// deriveArmorClass calcula la CA con la armadura y el escudo equipados.
+// Sin armadura aplica la Defensa sin armadura de bárbaros (CON) y monjes (SAB, sin escudo).
+func deriveArmorClass(char *models.Character, items []models.InventoryItem) int {
+	dexMod := abilityModifier(char.AbilityScores.Dexterity)
+	var armor, shield *models.InventoryItem
+
+	for i := range items {
+		if !items[i].Equipped || items[i].ArmorData == nil {
+			continue
+		}
+		if isShield(items[i]) {
+			shield = &items[i]
+		} else {
+			armor = &items[i]
+		}
+	}
+
+	ac := 10 + dexMod
+	if armor != nil {
+		data := armor.ArmorData
+		switch data.DexModifier {
+		case "none":
+			ac = data.BaseAC + data.MagicBonus
+		case "max2":
+			ac = data.BaseAC + minInt(dexMod, 2) + data.MagicBonus
+		default:
+			ac = data.BaseAC + dexMod + data.MagicBonus
+		}
+	} else {
+		class := strings.ToLower(char.Class)
+		switch {
+		case strings.Contains(class, "barbarian"), strings.Contains(class, "bárbaro"), strings.Contains(class, "barbaro"):
+			ac += abilityModifier(char.AbilityScores.Constitution)
+		case (strings.Contains(class, "monk") || strings.Contains(class, "monje")) && shield == nil:
+			ac += abilityModifier(char.AbilityScores.Wisdom)
+		}
+	}
+
+	if shield != nil {
+		ac += shield.ArmorData.BaseAC + shield.ArmorData.MagicBonus
+	}
+
+	return ac
+}
+
+// armorWarnings avisa de requisitos de Fuerza, desventaja en Sigilo y falta de competencia
+func armorWarnings(char *models.Character, items []models.InventoryItem) []string {
+	warnings := []string{}
+
+	for _, item := range items {
+		if !item.Equipped || item.ArmorData == nil {
+			continue
+		}
+		data := item.ArmorData
+
+		if data.StrengthRequirement > 0 && char.AbilityScores.Strength < data.StrengthRequirement {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s requiere Fuerza %d: la velocidad se reduce en 10 pies", item.Name, data.StrengthRequirement))
+		}
+		if data.StealthDisadvantage {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s impone desventaja en las pruebas de Sigilo", item.Name))
+		}
+
+		// Sin competencias registradas no se avisa (personajes antiguos)
+		if len(char.ArmorProficiencies) > 0 && !hasArmorProficiency(char, armorCategory(item)) {
+			warnings = append(warnings, fmt.Sprintf(
+				"Sin competencia con %s: desventaja en pruebas, salvaciones y ataques de FUE o DES y no se pueden lanzar conjuros", item.Name))
+		}
+	}
+
+	return warnings
+}
+
+func hasArmorProficiency(char *models.Character, category string) bool {
+	for _, prof := range char.ArmorProficiencies {
+		p := strings.ToLower(strings.TrimSpace(prof))
+		if p == category || p == category+"s" || strings.HasPrefix(p, category+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -313,6 +313,18 @@ func (h *Handler) DeleteEvent(c *gin.Context) {
 		totalDeleted += notesCount
 	}
 
+	// El historial de XP y las versiones quedan con los personajes que pasan al baúl;
+	// se eliminan junto con el personaje (DeleteCharacter)
+	totalDeleted += h.deleteCampaignDocs(ctx, "inventory_transfers", eventID)
+
+	// Eliminar fondo del grupo y tiendas
+	totalDeleted += h.deleteCampaignDocs(ctx, "party_items", eventID)
+	totalDeleted += h.deleteCampaignDocs(ctx, "merchants", eventID)
+	totalDeleted += h.deleteCampaignDocs(ctx, "homebrew", eventID)
+	if _, err := h.db.Collection("party_currencies").Doc(eventID).Delete(ctx); err != nil {
+		log.Printf("Error eliminando tesoro del grupo: %v", err)
+	}
+
 	// Eliminar encuentros y combatientes
 	encountersIter := h.db.Collection("encounters").
 		Where("campaignId", "==", eventID).
@@ -394,40 +406,9 @@ func (h *Handler) DeleteEvent(c *gin.Context) {
 		totalDeleted += encounterCount
 	}
 
-	// Eliminar personajes
-	charactersBatch := h.db.Batch()
-	charCount := 0
-
-	charactersIter := h.db.Collection("characters").
-		Where("campaignId", "==", eventID).
-		Documents(ctx)
-
-	for {
-		charDoc, err := charactersIter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err == nil {
-			charactersBatch.Delete(charDoc.Ref)
-			charCount++
-
-			if charCount >= 400 {
-				if _, err := charactersBatch.Commit(ctx); err != nil {
-					log.Printf("Error en batch commit: %v", err)
-				}
-				totalDeleted += charCount
-				charactersBatch = h.db.Batch()
-				charCount = 0
-			}
-		}
-	}
-
-	if charCount > 0 {
-		if _, err := charactersBatch.Commit(ctx); err != nil {
-			log.Printf("Error en batch commit: %v", err)
-		}
-		totalDeleted += charCount
-	}
+	// Devolver personajes al baúl de sus dueños (no se eliminan con la campaña)
+	vaulted := h.moveCharactersToVault(ctx, h.db.Collection("characters").
+		Where("campaignId", "==", eventID))
 
 	// Eliminar invitaciones
 	invitationsBatch := h.db.Batch()
@@ -517,15 +498,69 @@ func (h *Handler) DeleteEvent(c *gin.Context) {
 
 	// ✅ USAR HELPER DISTRIBUIDO
 	h.invalidateCampaignCache(ctx, eventID)
+	h.invalidateNoteIndex(ctx, eventID)
 
-	log.Printf("✅ Eliminación COMPLETA: %d documentos eliminados", totalDeleted)
+	log.Printf("✅ Eliminación COMPLETA: %d documentos eliminados, %d personajes al baúl", totalDeleted, vaulted)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":          "Campaña eliminada exitosamente",
-		"deletedDocuments": totalDeleted,
+		"message":           "Campaña eliminada exitosamente",
+		"deletedDocuments":  totalDeleted,
+		"vaultedCharacters": vaulted,
 	})
 }
 
+// deleteCampaignDocs elimina en batches todos los documentos de una colección que
+// pertenecen a la campaña (campo campaignId). Devuelve la cantidad eliminada.
+func (h *Handler) deleteCampaignDocs(ctx context.Context, collection, campaignID string) int {
+	return h.deleteDocsWhere(ctx, collection, "campaignId", campaignID)
+}
+
+// deleteDocsWhere elimina en batches los documentos de una colección cuyo campo es igual
+// al valor. Devuelve la cantidad eliminada.
+func (h *Handler) deleteDocsWhere(ctx context.Context, collection, field, value string) int {
+	iter := h.db.Collection(collection).
+		Where(field, "==", value).
+		Documents(ctx)
+
+	batch := h.db.Batch()
+	count := 0
+	deleted := 0
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Error iterando %s: %v", collection, err)
+			break
+		}
+
+		batch.Delete(doc.Ref)
+		count++
+
+		if count >= 400 {
+			if _, err := batch.Commit(ctx); err != nil {
+				log.Printf("Error en batch commit de %s: %v", collection, err)
+			} else {
+				deleted += count
+			}
+			batch = h.db.Batch()
+			count = 0
+		}
+	}
+
+	if count > 0 {
+		if _, err := batch.Commit(ctx); err != nil {
+			log.Printf("Error en batch commit final de %s: %v", collection, err)
+		} else {
+			deleted += count
+		}
+	}
+
+	return deleted
+}
+
 // ===========================
 // PARTICIPANTES
 // ===========================
@@ -738,37 +773,45 @@ func (h *Handler) RemovePlayer(c *gin.Context) {
 	batch := h.db.Batch()
 	batchCount := 0
 
-	charactersIter := h.db.Collection("characters").
+	notesIter := h.db.Collection("notes").
 		Where("campaignId", "==", eventID).
-		Where("userId", "==", playerID).
+		Where("authorId", "==", playerID).
+		Where("isShared", "==", false).
 		Documents(ctx)
 
 	for {
-		charDoc, err := charactersIter.Next()
+		noteDoc, err := notesIter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err == nil {
-			batch.Delete(charDoc.Ref)
+			batch.Delete(noteDoc.Ref)
 			batchCount++
 		}
 	}
 
-	notesIter := h.db.Collection("notes").
-		Where("campaignId", "==", eventID).
-		Where("authorId", "==", playerID).
-		Where("isShared", "==", false).
+	// Deja de ver las notas que el DM le reveló. Array-contains sin índice compuesto:
+	// la campaña se filtra acá.
+	revealedIter := h.db.Collection("notes").
+		Where("sharedWith", "array-contains", playerID).
 		Documents(ctx)
 
 	for {
-		noteDoc, err := notesIter.Next()
+		noteDoc, err := revealedIter.Next()
 		if err == iterator.Done {
 			break
 		}
-		if err == nil {
-			batch.Delete(noteDoc.Ref)
-			batchCount++
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando notas"})
+			return
+		}
+		var note models.Note
+		if noteDoc.DataTo(&note) != nil || note.CampaignID != eventID {
+			continue
 		}
+		batch.Update(noteDoc.Ref, []firestore.Update{
+			{Path: "sharedWith", Value: firestore.ArrayRemove(playerID)},
+		})
 	}
 
 	iter := h.db.Collection("event_members").
@@ -793,6 +836,13 @@ func (h *Handler) RemovePlayer(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando jugador"})
 		return
 	}
+	// El índice de búsqueda en caché todavía tiene las notas eliminadas
+	h.invalidateNoteIndex(ctx, eventID)
+
+	// Sus personajes vuelven a su baúl en lugar de eliminarse
+	vaulted := h.moveCharactersToVault(ctx, h.db.Collection("characters").
+		Where("campaignId", "==", eventID).
+		Where("userId", "==", playerID))
 
 	if _, err := h.db.Collection("events").Doc(eventID).Update(ctx, []firestore.Update{
 		{Path: "playerIds", Value: firestore.ArrayRemove(playerID)},
@@ -805,10 +855,13 @@ func (h *Handler) RemovePlayer(c *gin.Context) {
 	h.invalidateCampaignCache(ctx, eventID)
 	h.invalidatePattern(ctx, "members:"+eventID)
 
-	log.Printf("✅ Jugador removido. Documentos eliminados: %d", batchCount)
+	h.invalidatePattern(ctx, "characters:"+eventID)
+
+	log.Printf("✅ Jugador removido. Documentos eliminados: %d, personajes al baúl: %d", batchCount, vaulted)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":          "Jugador eliminado",
-		"deletedDocuments": batchCount,
+		"message":           "Jugador eliminado",
+		"deletedDocuments":  batchCount,
+		"vaultedCharacters": vaulted,
 	})
 }
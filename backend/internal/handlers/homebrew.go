@@ -0,0 +1,629 @@
+// backend/internal/handlers/homebrew.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/compendium"
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// HOMEBREW
+// ===========================
+
+const (
+	MAX_HOMEBREW_PER_USER = 1000
+)
+
+var (
+	errHomebrewNotFound  = errors.New("entrada homebrew no encontrada")
+	errHomebrewContent   = errors.New("el contenido no coincide con el tipo de entrada")
+	errHomebrewDuplicate = errors.New("ya existe una entrada con ese slug")
+	errHomebrewSlug      = errors.New("el nombre o slug no genera un slug válido")
+	errHomebrewFull      = fmt.Errorf("límite de %d entradas homebrew alcanzado", MAX_HOMEBREW_PER_USER)
+)
+
+// GetMyHomebrew - Listar las entradas homebrew del usuario (filtros: kind, campaignId; "shared" = compartidas)
+func (h *Handler) GetMyHomebrew(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	ctx := context.Background()
+
+	query := h.db.Collection("homebrew").Where("ownerId", "==", uid)
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind", "==", kind)
+	}
+	if campaignID := c.Query("campaignId"); campaignID != "" {
+		if campaignID == "shared" {
+			campaignID = ""
+		}
+		query = query.Where("campaignId", "==", campaignID)
+	}
+
+	entries, err := homebrewFromQuery(ctx, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo homebrew"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// CreateHomebrew - El DM crea contenido para la campaña (o compartido entre sus campañas)
+func (h *Handler) CreateHomebrew(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.HomebrewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := buildHomebrewEntry(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	entry.ID = generateID()
+	entry.OwnerID = uid
+	if !req.Shared {
+		entry.CampaignID = campaignID
+	}
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		count, err := h.homebrewCountInTx(tx, uid)
+		if err != nil {
+			return err
+		}
+		if count >= MAX_HOMEBREW_PER_USER {
+			return errHomebrewFull
+		}
+		existing, err := h.homebrewBySlugInTx(tx, uid, entry.CampaignID, entry.Kind, entry.Slug)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return errHomebrewDuplicate
+		}
+		return tx.Create(h.db.Collection("homebrew").Doc(entry.ID), entry)
+	})
+
+	if err != nil {
+		respondHomebrewError(c, err, "Error creando homebrew")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateHomebrew - Reemplazar el contenido de una entrada (solo su dueño; conserva el alcance)
+func (h *Handler) UpdateHomebrew(c *gin.Context) {
+	uid := c.GetString("uid")
+	homebrewID := c.Param("homebrewId")
+	ctx := context.Background()
+
+	var req models.HomebrewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := buildHomebrewEntry(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ref := h.db.Collection("homebrew").Doc(homebrewID)
+	var entry models.HomebrewEntry
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return errHomebrewNotFound
+		}
+		if err := doc.DataTo(&entry); err != nil {
+			return err
+		}
+		if entry.OwnerID != uid {
+			return errHomebrewNotFound
+		}
+
+		existing, err := h.homebrewBySlugInTx(tx, uid, entry.CampaignID, updated.Kind, updated.Slug)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != entry.ID {
+			return errHomebrewDuplicate
+		}
+
+		updated.ID = entry.ID
+		updated.OwnerID = entry.OwnerID
+		updated.CampaignID = entry.CampaignID
+		updated.CreatedAt = entry.CreatedAt
+		updated.UpdatedAt = time.Now()
+		entry = updated
+		return tx.Set(ref, entry)
+	})
+
+	if err != nil {
+		respondHomebrewError(c, err, "Error actualizando homebrew")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteHomebrew - Eliminar una entrada (solo su dueño). Los items ya creados a partir
+// de ella se conservan.
+func (h *Handler) DeleteHomebrew(c *gin.Context) {
+	uid := c.GetString("uid")
+	homebrewID := c.Param("homebrewId")
+	ctx := context.Background()
+
+	ref := h.db.Collection("homebrew").Doc(homebrewID)
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errHomebrewNotFound.Error()})
+		return
+	}
+	var entry models.HomebrewEntry
+	if err := doc.DataTo(&entry); err != nil || entry.OwnerID != uid {
+		c.JSON(http.StatusNotFound, gin.H{"error": errHomebrewNotFound.Error()})
+		return
+	}
+
+	if _, err := ref.Delete(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando homebrew"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entrada eliminada"})
+}
+
+// ExportHomebrewPack - Exportar como paquete JSON el homebrew visible en la campaña
+func (h *Handler) ExportHomebrewPack(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	entries, err := h.campaignHomebrew(ctx, campaignID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error exportando homebrew"})
+		return
+	}
+
+	pack := models.HomebrewPack{
+		Name:       c.DefaultQuery("name", "Homebrew"),
+		ExportedAt: time.Now(),
+		Items:      []models.CompendiumItem{},
+		Monsters:   []models.CompendiumMonster{},
+		Spells:     []models.CompendiumSpell{},
+	}
+	for _, entry := range entries {
+		switch entry.Kind {
+		case models.HomebrewKindItem:
+			pack.Items = append(pack.Items, *entry.Item)
+		case models.HomebrewKindMonster:
+			pack.Monsters = append(pack.Monsters, *entry.Monster)
+		case models.HomebrewKindSpell:
+			pack.Spells = append(pack.Spells, *entry.Spell)
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", compendium.Slugify(pack.Name)+".json"))
+	c.JSON(http.StatusOK, pack)
+}
+
+// ImportHomebrewPack - Importar un paquete JSON en la campaña (?shared=true lo comparte
+// entre las campañas del DM). Las entradas con el mismo tipo y slug se actualizan; las
+// inválidas se informan en errors sin frenar el resto.
+func (h *Handler) ImportHomebrewPack(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var pack models.HomebrewPack
+	if err := c.ShouldBindJSON(&pack); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scope := campaignID
+	if c.Query("shared") == "true" {
+		scope = ""
+	}
+
+	requests := []models.HomebrewRequest{}
+	for i := range pack.Items {
+		requests = append(requests, models.HomebrewRequest{Kind: models.HomebrewKindItem, Item: &pack.Items[i]})
+	}
+	for i := range pack.Monsters {
+		requests = append(requests, models.HomebrewRequest{Kind: models.HomebrewKindMonster, Monster: &pack.Monsters[i]})
+	}
+	for i := range pack.Spells {
+		requests = append(requests, models.HomebrewRequest{Kind: models.HomebrewKindSpell, Spell: &pack.Spells[i]})
+	}
+
+	response := models.ImportPackResponse{Errors: []string{}}
+	entries := []models.HomebrewEntry{}
+	seen := map[string]bool{}
+	for _, req := range requests {
+		entry, err := buildHomebrewEntry(req)
+		if err != nil {
+			response.Errors = append(response.Errors, fmt.Sprintf("%s: %v", homebrewRequestName(req), err))
+			continue
+		}
+		key := entry.Kind + "/" + entry.Slug
+		if seen[key] {
+			response.Errors = append(response.Errors, fmt.Sprintf("%s: slug repetido en el paquete", key))
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, entry)
+	}
+
+	// Entradas existentes del usuario en el alcance destino, por tipo y slug
+	existing := map[string]models.HomebrewEntry{}
+	current, err := homebrewFromQuery(ctx, h.db.Collection("homebrew").
+		Where("ownerId", "==", uid).
+		Where("campaignId", "==", scope))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error importando homebrew"})
+		return
+	}
+	for _, entry := range current {
+		existing[entry.Kind+"/"+entry.Slug] = entry
+	}
+
+	total, err := homebrewFromQuery(ctx, h.db.Collection("homebrew").Where("ownerId", "==", uid))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error importando homebrew"})
+		return
+	}
+	count := len(total)
+
+	now := time.Now()
+	batch := h.db.Batch()
+	pending := 0
+	for _, entry := range entries {
+		if previous, ok := existing[entry.Kind+"/"+entry.Slug]; ok {
+			entry.ID = previous.ID
+			entry.CreatedAt = previous.CreatedAt
+			response.Updated++
+		} else {
+			if count >= MAX_HOMEBREW_PER_USER {
+				response.Errors = append(response.Errors, fmt.Sprintf("%s/%s: %v", entry.Kind, entry.Slug, errHomebrewFull))
+				continue
+			}
+			entry.ID = generateID()
+			entry.CreatedAt = now
+			count++
+			response.Created++
+		}
+		entry.OwnerID = uid
+		entry.CampaignID = scope
+		entry.UpdatedAt = now
+		batch.Set(h.db.Collection("homebrew").Doc(entry.ID), entry)
+		pending++
+
+		if pending >= 400 {
+			if _, err := batch.Commit(ctx); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error importando homebrew"})
+				return
+			}
+			batch = h.db.Batch()
+			pending = 0
+		}
+	}
+	if pending > 0 {
+		if _, err := batch.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error importando homebrew"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ===========================
+// COMPENDIO DE LA CAMPAÑA (SRD + HOMEBREW)
+// ===========================
+
+// GetCampaignCompendiumItems - Buscar items del SRD y del homebrew visible en la campaña.
+// Un item homebrew con el mismo slug que uno del SRD lo reemplaza.
+func (h *Handler) GetCampaignCompendiumItems(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	filter, err := compendiumFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.campaignHomebrew(ctx, campaignID, models.HomebrewKindItem)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo homebrew"})
+		return
+	}
+
+	homebrew := map[string]bool{}
+	results := []compendium.Item{}
+	for _, entry := range entries {
+		if homebrew[entry.Slug] || !compendium.Matches(*entry.Item, filter) {
+			continue
+		}
+		homebrew[entry.Slug] = true
+		item := *entry.Item
+		item.Source = models.SourceHomebrew
+		results = append(results, item)
+	}
+	for _, item := range compendium.Default().Filter(filter) {
+		if !homebrew[item.Slug] {
+			results = append(results, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, compendium.Paginate(results, filter))
+}
+
+// GetCampaignMonsters - Criaturas homebrew visibles en la campaña (q filtra por nombre)
+func (h *Handler) GetCampaignMonsters(c *gin.Context) {
+	entries, err := h.campaignHomebrew(context.Background(), c.Param("id"), models.HomebrewKindMonster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo homebrew"})
+		return
+	}
+
+	query := strings.ToLower(strings.TrimSpace(c.Query("q")))
+	monsters := []models.CompendiumMonster{}
+	for _, entry := range entries {
+		if query == "" || strings.Contains(strings.ToLower(entry.Name), query) {
+			monster := *entry.Monster
+			monster.Source = models.SourceHomebrew
+			monsters = append(monsters, monster)
+		}
+	}
+	c.JSON(http.StatusOK, monsters)
+}
+
+// GetCampaignSpells - Conjuros homebrew visibles en la campaña (q filtra por nombre, level por nivel)
+func (h *Handler) GetCampaignSpells(c *gin.Context) {
+	entries, err := h.campaignHomebrew(context.Background(), c.Param("id"), models.HomebrewKindSpell)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo homebrew"})
+		return
+	}
+
+	query := strings.ToLower(strings.TrimSpace(c.Query("q")))
+	level := c.Query("level")
+	spells := []models.CompendiumSpell{}
+	for _, entry := range entries {
+		if query != "" && !strings.Contains(strings.ToLower(entry.Name), query) {
+			continue
+		}
+		if level != "" && fmt.Sprint(entry.Spell.Level) != level {
+			continue
+		}
+		spell := *entry.Spell
+		spell.Source = models.SourceHomebrew
+		spells = append(spells, spell)
+	}
+	c.JSON(http.StatusOK, spells)
+}
+
+// ===========================
+// HELPERS DE HOMEBREW
+// ===========================
+
+// buildHomebrewEntry valida el contenido del request y arma la entrada (sin IDs ni alcance)
+func buildHomebrewEntry(req models.HomebrewRequest) (models.HomebrewEntry, error) {
+	entry := models.HomebrewEntry{Kind: req.Kind}
+
+	// Los paquetes no validan sus entradas al hacer bind
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return entry, err
+	}
+
+	switch req.Kind {
+	case models.HomebrewKindItem:
+		if req.Item == nil || req.Monster != nil || req.Spell != nil {
+			return entry, errHomebrewContent
+		}
+		item := *req.Item
+		item.Name = strings.TrimSpace(item.Name)
+		item.Slug = homebrewSlug(item.Slug, item.Name)
+		item.Source = ""
+		inventoryItem := compendium.ToInventoryItem(item)
+		if err := validateItem(&inventoryItem); err != nil {
+			return entry, err
+		}
+		entry.Slug, entry.Name, entry.Item = item.Slug, item.Name, &item
+
+	case models.HomebrewKindMonster:
+		if req.Monster == nil || req.Item != nil || req.Spell != nil {
+			return entry, errHomebrewContent
+		}
+		monster := *req.Monster
+		monster.Name = strings.TrimSpace(monster.Name)
+		monster.Slug = homebrewSlug(monster.Slug, monster.Name)
+		monster.Source = ""
+		if monster.HitDice != "" {
+			if _, err := parseDice(monster.HitDice); err != nil {
+				return entry, fmt.Errorf("hitDice: %w", err)
+			}
+		}
+		for _, action := range monster.Actions {
+			if action.DamageDice == "" {
+				continue
+			}
+			if _, err := parseDice(action.DamageDice); err != nil {
+				return entry, fmt.Errorf("%s: %w", action.Name, err)
+			}
+		}
+		entry.Slug, entry.Name, entry.Monster = monster.Slug, monster.Name, &monster
+
+	case models.HomebrewKindSpell:
+		if req.Spell == nil || req.Item != nil || req.Monster != nil {
+			return entry, errHomebrewContent
+		}
+		spell := *req.Spell
+		spell.Name = strings.TrimSpace(spell.Name)
+		spell.Slug = homebrewSlug(spell.Slug, spell.Name)
+		spell.Source = ""
+		spell.Classes = uniqueStrings(spell.Classes)
+		entry.Slug, entry.Name, entry.Spell = spell.Slug, spell.Name, &spell
+
+	default:
+		return entry, errHomebrewContent
+	}
+
+	if entry.Name == "" || entry.Slug == "" {
+		return entry, errHomebrewSlug
+	}
+	return entry, nil
+}
+
+// homebrewSlug normaliza el slug o lo genera a partir del nombre
+func homebrewSlug(slug, name string) string {
+	if slug = compendium.Slugify(slug); slug != "" {
+		return slug
+	}
+	return compendium.Slugify(name)
+}
+
+func homebrewRequestName(req models.HomebrewRequest) string {
+	switch {
+	case req.Item != nil:
+		return req.Kind + "/" + req.Item.Name
+	case req.Monster != nil:
+		return req.Kind + "/" + req.Monster.Name
+	case req.Spell != nil:
+		return req.Kind + "/" + req.Spell.Name
+	}
+	return req.Kind
+}
+
+// campaignHomebrew devuelve el homebrew visible en la campaña: el propio de la campaña
+// primero y después el compartido por su DM. kind "" = todos los tipos.
+func (h *Handler) campaignHomebrew(ctx context.Context, campaignID, kind string) ([]models.HomebrewEntry, error) {
+	campaignDoc, err := h.db.Collection("events").Doc(campaignID).Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var campaign models.Campaign
+	if err := campaignDoc.DataTo(&campaign); err != nil {
+		return nil, err
+	}
+
+	scoped := h.db.Collection("homebrew").Where("campaignId", "==", campaignID)
+	shared := h.db.Collection("homebrew").Where("ownerId", "==", campaign.DmID).Where("campaignId", "==", "")
+	if kind != "" {
+		scoped = scoped.Where("kind", "==", kind)
+		shared = shared.Where("kind", "==", kind)
+	}
+
+	entries, err := homebrewFromQuery(ctx, scoped)
+	if err != nil {
+		return nil, err
+	}
+	sharedEntries, err := homebrewFromQuery(ctx, shared)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, sharedEntries...), nil
+}
+
+// homebrewItemBySlug busca un item por slug entre las entradas homebrew de la campaña
+func homebrewItemBySlug(entries []models.HomebrewEntry, slug string) *models.CompendiumItem {
+	slug = compendium.Slugify(slug)
+	for _, entry := range entries {
+		if entry.Slug == slug && entry.Item != nil {
+			return entry.Item
+		}
+	}
+	return nil
+}
+
+func homebrewFromQuery(ctx context.Context, query firestore.Query) ([]models.HomebrewEntry, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	entries := []models.HomebrewEntry{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry models.HomebrewEntry
+		if err := doc.DataTo(&entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (h *Handler) homebrewCountInTx(tx *firestore.Transaction, uid string) (int, error) {
+	docs, err := tx.Documents(h.db.Collection("homebrew").Where("ownerId", "==", uid)).GetAll()
+	if err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+// homebrewBySlugInTx busca la entrada del usuario con ese tipo y slug en el alcance (nil si no hay)
+func (h *Handler) homebrewBySlugInTx(tx *firestore.Transaction, uid, campaignID, kind, slug string) (*models.HomebrewEntry, error) {
+	doc, err := tx.Documents(h.db.Collection("homebrew").
+		Where("ownerId", "==", uid).
+		Where("campaignId", "==", campaignID).
+		Where("kind", "==", kind).
+		Where("slug", "==", slug).
+		Limit(1)).Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry models.HomebrewEntry
+	if err := doc.DataTo(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func respondHomebrewError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errHomebrewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errHomebrewDuplicate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errHomebrewFull):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
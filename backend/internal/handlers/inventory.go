@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -66,18 +67,34 @@ func (h *Handler) CreateItem(c *gin.Context) {
 		}
 	}
 
+	if err := h.applyCompendium(ctx, character.CampaignID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := newItemFromRequest(req)
+	if err := validateItem(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	itemsIter := h.db.Collection("inventory_items").
 		Where("characterId", "==", characterID).
 		Documents(ctx)
 
 	itemCount := 0
+	var existingItems []models.InventoryItem
 	for {
-		_, err := itemsIter.Next()
+		doc, err := itemsIter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err == nil {
 			itemCount++
+			var existing models.InventoryItem
+			if doc.DataTo(&existing) == nil {
+				existingItems = append(existingItems, existing)
+			}
 		}
 	}
 
@@ -89,88 +106,44 @@ func (h *Handler) CreateItem(c *gin.Context) {
 		return
 	}
 
-	if req.Open5eSlug != "" {
-		// Buscar por slug de Open5e
-		existingIter := h.db.Collection("inventory_items").
-			Where("characterId", "==", characterID).
-			Where("open5eSlug", "==", req.Open5eSlug).
-			Limit(1).
-			Documents(ctx)
-
-		if existingDoc, err := existingIter.Next(); err != iterator.Done {
-			// Item idéntico encontrado, incrementar quantity
-			var existing models.InventoryItem
-			if err := existingDoc.DataTo(&existing); err == nil {
-				newQuantity := existing.Quantity + req.Quantity
-
-				_, updateErr := h.db.Collection("inventory_items").Doc(existing.ID).Update(ctx, []firestore.Update{
-					{Path: "quantity", Value: newQuantity},
-					{Path: "updatedAt", Value: time.Now()},
-				})
-
-				if updateErr == nil {
-					// Invalidar cache
-					h.invalidateCharacterCache(ctx, characterID)
-
-					// Devolver item actualizado
-					existing.Quantity = newQuantity
-					existing.UpdatedAt = time.Now()
-					c.JSON(http.StatusCreated, existing)
-					return
-				}
-			}
+	if req.ContainerID != "" {
+		if err := validateContainerPlacement(existingItems, item, req.ContainerID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-	} else {
-		// Buscar por nombre exacto (para items manuales)
-		existingIter := h.db.Collection("inventory_items").
-			Where("characterId", "==", characterID).
-			Where("name", "==", req.Name).
-			Where("type", "==", req.Type).
-			Limit(1).
-			Documents(ctx)
+	}
 
-		if existingDoc, err := existingIter.Next(); err != iterator.Done {
-			var existing models.InventoryItem
-			if err := existingDoc.DataTo(&existing); err == nil {
-				// Solo stackear si NO tiene datos de arma/armadura
-				if existing.WeaponData == nil && existing.ArmorData == nil {
-					newQuantity := existing.Quantity + req.Quantity
-
-					_, updateErr := h.db.Collection("inventory_items").Doc(existing.ID).Update(ctx, []firestore.Update{
-						{Path: "quantity", Value: newQuantity},
-						{Path: "updatedAt", Value: time.Now()},
-					})
-
-					if updateErr == nil {
-						h.invalidateCharacterCache(ctx, characterID)
-
-						existing.Quantity = newQuantity
-						existing.UpdatedAt = time.Now()
-						c.JSON(http.StatusCreated, existing)
-						return
-					}
-				}
-			}
+	// Item idéntico en el mismo contenedor (mismo slug, o mismo nombre y tipo sin datos
+	// de arma/armadura): incrementar quantity. Los objetos con cargas y los contenedores
+	// no se apilan, y una pila llena (MAX_ITEM_QUANTITY) deja paso a una nueva.
+	if stack := findStack(existingItems, item, item.Quantity); stack != -1 {
+		existing := existingItems[stack]
+		newQuantity := existing.Quantity + item.Quantity
+
+		_, updateErr := h.db.Collection("inventory_items").Doc(existing.ID).Update(ctx, []firestore.Update{
+			{Path: "quantity", Value: newQuantity},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+
+		if updateErr == nil {
+			// Invalidar cache
+			h.invalidateCharacterCache(ctx, characterID)
+
+			// Devolver item actualizado
+			existing.Quantity = newQuantity
+			existing.UpdatedAt = time.Now()
+			c.JSON(http.StatusCreated, existing)
+			return
 		}
 	}
 
 	// Crear item
 	itemRef := h.db.Collection("inventory_items").NewDoc()
-	item := models.InventoryItem{
-		ID:          itemRef.ID,
-		CharacterID: characterID,
-		CampaignID:  character.CampaignID,
-		Name:        req.Name,
-		Type:        models.ItemType(req.Type),
-		Description: req.Description,
-		Quantity:    req.Quantity,
-		Value:       req.Value,
-		WeaponData:  req.WeaponData,
-		ArmorData:   req.ArmorData,
-		Open5eSlug:  req.Open5eSlug,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+	item.ID = itemRef.ID
+	item.CharacterID = characterID
+	item.CampaignID = character.CampaignID
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = time.Now()
 
 	if _, err := itemRef.Set(ctx, item); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando item"})
@@ -212,7 +185,6 @@ func (h *Handler) GetCharacterInventory(c *gin.Context) {
 		Documents(ctx)
 
 	var items []models.InventoryItem
-	totalValue := 0.0
 
 	for {
 		doc, err := itemsIter.Next()
@@ -230,7 +202,6 @@ func (h *Handler) GetCharacterInventory(c *gin.Context) {
 		}
 
 		items = append(items, item)
-		totalValue += item.Value * float64(item.Quantity)
 	}
 
 	if items == nil {
@@ -242,17 +213,21 @@ func (h *Handler) GetCharacterInventory(c *gin.Context) {
 	currencyDoc, err := h.db.Collection("currencies").Doc(characterID).Get(ctx)
 	if err == nil {
 		currencyDoc.DataTo(&currency)
-		// Agregar valor de la moneda al total
-		totalValue += float64(currency.Copper)/100.0 +
-			float64(currency.Silver)/10.0 +
-			float64(currency.Gold) +
-			float64(currency.Platinum)*10.0
 	}
+	itemsWorth, coinsWorth, netWorth := inventoryWorth(items, currency)
+
+	encumbrance := computeEncumbrance(character.AbilityScores, items, currency)
 
 	response := models.InventoryResponse{
-		Items:      items,
-		Currency:   currency,
-		TotalValue: totalValue,
+		Items:       items,
+		Currency:    currency,
+		TotalValue:  float64(netWorth.TotalCP) / 100,
+		ItemsWorth:  itemsWorth,
+		CoinsWorth:  coinsWorth,
+		NetWorth:    netWorth,
+		TotalWeight: encumbrance.CarriedWeight,
+		Encumbrance: encumbrance,
+		Tree:        buildInventoryTree(items),
 	}
 
 	h.cache.SetInventory(characterID, response)
@@ -260,99 +235,201 @@ func (h *Handler) GetCharacterInventory(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// UpdateItem - Actualizar item (cantidad)
-func (h *Handler) UpdateItem(c *gin.Context) {
-	uid := c.GetString("uid")
-	if uid == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+// ReplaceItem - Reemplazar todos los datos de un item conservando su ID
+func (h *Handler) ReplaceItem(c *gin.Context) {
+	var req models.CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	itemID := c.Param("itemId")
-	ctx := context.Background()
 
-	var req struct {
-		Name        *string  `json:"name"`
-		Description *string  `json:"description"`
-		Quantity    *int     `json:"quantity"`
-		Value       *float64 `json:"value"`
-	}
+	h.editItem(c, func(item *models.InventoryItem) (bool, error) {
+		// El homebrew visible depende de la campaña del item: se resuelve ya verificados
+		// los permisos, sobre una copia por si la transacción se reintenta
+		resolved := req
+		if err := h.applyCompendium(context.Background(), item.CampaignID, &resolved); err != nil {
+			return false, err
+		}
+
+		replacement := newItemFromRequest(resolved)
+		replacement.ID = item.ID
+		replacement.CharacterID = item.CharacterID
+		replacement.CampaignID = item.CampaignID
+		replacement.Equipped = item.Equipped
+		replacement.Attuned = item.Attuned
+		replacement.CreatedAt = item.CreatedAt
+		*item = replacement
+		return false, nil
+	})
+}
 
+// UpdateItem - Actualizar solo los campos enviados (cantidad <= 0 elimina el item)
+func (h *Handler) UpdateItem(c *gin.Context) {
+	var req models.UpdateItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Obtener item
-	itemDoc, err := h.db.Collection("inventory_items").Doc(itemID).Get(ctx)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Item no encontrado"})
-		return
-	}
+	h.editItem(c, func(item *models.InventoryItem) (bool, error) {
+		if req.Quantity != nil && *req.Quantity <= 0 {
+			return true, nil
+		}
 
-	var item models.InventoryItem
-	if err := itemDoc.DataTo(&item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando item"})
+		if req.Name != nil {
+			item.Name = *req.Name
+		}
+		if req.Type != nil && models.ItemType(*req.Type) != item.Type {
+			item.Type = models.ItemType(*req.Type)
+			// Al cambiar de tipo se descartan los datos que ya no corresponden
+			if item.Type != models.ItemTypeWeapon {
+				item.WeaponData = nil
+			}
+			if item.Type != models.ItemTypeArmor && item.Type != models.ItemTypeShield {
+				item.ArmorData = nil
+			}
+			if item.Type != models.ItemTypeContainer {
+				item.ContainerData = nil
+			}
+			if item.Type != models.ItemTypeConsumable {
+				item.Effect = nil
+			}
+		}
+		if req.Description != nil {
+			item.Description = *req.Description
+		}
+		if req.Quantity != nil {
+			item.Quantity = *req.Quantity
+		}
+		if req.Value != nil {
+			item.Value = *req.Value
+			item.PriceCP = gpToCP(*req.Value)
+			item.Denomination = ""
+		}
+		if req.Price != nil {
+			// Value se deriva del precio: sin esto un precio 0 volvería al value anterior
+			item.PriceCP = req.Price.Amount * denominationValue(req.Price.Denomination)
+			item.Denomination = req.Price.Denomination
+			item.Value = float64(item.PriceCP) / 100
+		}
+		if req.Weight != nil {
+			item.Weight = *req.Weight
+		}
+		if req.Volume != nil {
+			item.Volume = *req.Volume
+		}
+		if req.WeaponData != nil {
+			item.WeaponData = req.WeaponData
+		}
+		if req.ArmorData != nil {
+			item.ArmorData = req.ArmorData
+		}
+		if req.ContainerData != nil {
+			item.ContainerData = req.ContainerData
+		}
+		if req.MagicData != nil {
+			item.MagicData = req.MagicData
+		}
+		if req.Effect != nil {
+			item.Effect = req.Effect
+		}
+		if req.Open5eSlug != nil {
+			item.Open5eSlug = *req.Open5eSlug
+		}
+		return false, nil
+	})
+}
+
+// editItem aplica apply al item dentro de una transacción, valida el resultado
+// (datos por tipo, capacidad de contenedores) y lo guarda. Si apply devuelve
+// true el item se elimina; si devuelve error, la transacción se cancela.
+func (h *Handler) editItem(c *gin.Context, apply func(item *models.InventoryItem) (bool, error)) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
 		return
 	}
+	itemID := c.Param("itemId")
+	ctx := context.Background()
 
-	// Verificar permisos
-	charDoc, _ := h.db.Collection("characters").Doc(item.CharacterID).Get(ctx)
-	var character models.Character
-	charDoc.DataTo(&character)
+	itemRef := h.db.Collection("inventory_items").Doc(itemID)
+	var original, updated models.InventoryItem
+	deleted := false
 
-	if character.UserID != uid {
-		campaignDoc, _ := h.db.Collection("events").Doc(character.CampaignID).Get(ctx)
-		var campaign models.Campaign
-		if campaignDoc.DataTo(&campaign) != nil || campaign.DmID != uid {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
-			return
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		deleted = false
+
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errTransferItemNotFound
+		}
+		if err := itemDoc.DataTo(&original); err != nil {
+			return err
 		}
-	}
 
-	// Construir updates
-	updates := []firestore.Update{
-		{Path: "updatedAt", Value: time.Now()},
-	}
+		if _, err := h.itemOwnerInTx(tx, uid, original.CharacterID); err != nil {
+			return err
+		}
 
-	if req.Name != nil && *req.Name != "" {
-		updates = append(updates, firestore.Update{Path: "name", Value: *req.Name})
-	}
+		updated = original
+		remove, err := apply(&updated)
+		if err != nil {
+			return err
+		}
+		if remove {
+			deleted = true
+			return tx.Delete(itemRef)
+		}
 
-	if req.Description != nil {
-		updates = append(updates, firestore.Update{Path: "description", Value: *req.Description})
-	}
+		if err := validateItem(&updated); err != nil {
+			return err
+		}
 
-	if req.Quantity != nil {
-		if *req.Quantity <= 0 {
-			// Si la cantidad es 0 o negativa, eliminar el item
-			if _, err := h.db.Collection("inventory_items").Doc(itemID).Delete(ctx); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando item"})
-				return
+		items, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", original.CharacterID))
+		if err != nil {
+			return err
+		}
+		if updated.ContainerID != "" {
+			if err := validateContainerPlacement(items, updated, updated.ContainerID); err != nil {
+				return err
 			}
-			h.invalidateCharacterCache(ctx, item.CharacterID)
+		}
+		if err := validateContainerContents(items, updated); err != nil {
+			return err
+		}
 
-			c.JSON(http.StatusOK, gin.H{"message": "Item eliminado", "deleted": true})
-			return
+		updated.UpdatedAt = time.Now()
+		return tx.Set(itemRef, updated)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errTransferItemNotFound), errors.Is(err, errVaultCharacterNotFound), errors.Is(err, errContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errTransferForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
+		case errors.Is(err, errInvalidItem), errors.Is(err, errUnknownSlug), errors.Is(err, errNotAContainer), errors.Is(err, errContainerCycle),
+			errors.Is(err, errContainerWeightLimit), errors.Is(err, errContainerVolumeLimit), errors.Is(err, errContainerHasContents):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando item"})
 		}
-		updates = append(updates, firestore.Update{Path: "quantity", Value: *req.Quantity})
+		return
 	}
 
-	if req.Value != nil && *req.Value >= 0 {
-		updates = append(updates, firestore.Update{Path: "value", Value: *req.Value})
+	if original.Equipped && (original.ArmorData != nil || (!deleted && updated.ArmorData != nil)) {
+		h.refreshArmorClass(ctx, original.CharacterID, uid)
+	}
+	if deleted {
+		h.releaseContainerContents(ctx, original)
 	}
+	h.invalidateCharacterCache(ctx, original.CharacterID)
 
-	if _, err := h.db.Collection("inventory_items").Doc(itemID).Update(ctx, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando item"})
+	if deleted {
+		c.JSON(http.StatusOK, gin.H{"message": "Item eliminado", "deleted": true})
 		return
 	}
 
-	h.invalidateCharacterCache(ctx, item.CharacterID)
-
-	// Obtener item actualizado
-	updatedDoc, _ := h.db.Collection("inventory_items").Doc(itemID).Get(ctx)
-	var updated models.InventoryItem
-	updatedDoc.DataTo(&updated)
-
 	c.JSON(http.StatusOK, updated)
 }
 
@@ -374,20 +451,26 @@ func (h *Handler) DeleteItem(c *gin.Context) {
 	}
 
 	var item models.InventoryItem
-	itemDoc.DataTo(&item)
+	if err := itemDoc.DataTo(&item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando item"})
+		return
+	}
 
 	// Verificar permisos
-	charDoc, _ := h.db.Collection("characters").Doc(item.CharacterID).Get(ctx)
+	charDoc, err := h.db.Collection("characters").Doc(item.CharacterID).Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
 	var character models.Character
-	charDoc.DataTo(&character)
+	if err := charDoc.DataTo(&character); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando personaje"})
+		return
+	}
 
-	if character.UserID != uid {
-		campaignDoc, _ := h.db.Collection("events").Doc(character.CampaignID).Get(ctx)
-		var campaign models.Campaign
-		if campaignDoc.DataTo(&campaign) != nil || campaign.DmID != uid {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
-			return
-		}
+	if !h.characterEditableBy(ctx, character, uid) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
+		return
 	}
 
 	if _, err := h.db.Collection("inventory_items").Doc(itemID).Delete(ctx); err != nil {
@@ -395,6 +478,11 @@ func (h *Handler) DeleteItem(c *gin.Context) {
 		return
 	}
 
+	if item.Equipped && item.ArmorData != nil {
+		h.refreshArmorClass(ctx, item.CharacterID, uid)
+	}
+	h.releaseContainerContents(ctx, item)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Item eliminado"})
 }
 
@@ -402,7 +490,7 @@ func (h *Handler) DeleteItem(c *gin.Context) {
 // CURRENCY
 // ===========================
 
-// UpdateCurrency - Actualizar moneda del personaje
+// UpdateCurrency - Fijar la moneda del personaje (dentro de una transacción y registrado en el libro)
 func (h *Handler) UpdateCurrency(c *gin.Context) {
 	uid := c.GetString("uid")
 	if uid == "" {
@@ -427,36 +515,40 @@ func (h *Handler) UpdateCurrency(c *gin.Context) {
 	}
 
 	var character models.Character
-	charDoc.DataTo(&character)
+	if err := charDoc.DataTo(&character); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando personaje"})
+		return
+	}
 
-	if character.UserID != uid {
-		campaignDoc, _ := h.db.Collection("events").Doc(character.CampaignID).Get(ctx)
-		var campaign models.Campaign
-		if campaignDoc.DataTo(&campaign) != nil || campaign.DmID != uid {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
-			return
-		}
+	if !h.characterEditableBy(ctx, character, uid) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Sin permisos"})
+		return
+	}
+
+	reason := "Ajuste manual"
+	if req.Reason != nil && *req.Reason != "" {
+		reason = *req.Reason
 	}
 
-	// Obtener currency actual o crear nueva
 	currencyRef := h.db.Collection("currencies").Doc(characterID)
-	currencyDoc, err := currencyRef.Get(ctx)
+	var currency models.Currency
 
-	if err != nil {
-		// No existe, crear nueva
-		currency := models.Currency{
-			Copper:   0,
-			Silver:   0,
-			Gold:     0,
-			Platinum: 0,
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		before, err := currencyInTx(tx, currencyRef)
+		if err != nil {
+			return err
 		}
 
+		currency = before
 		if req.Copper != nil {
 			currency.Copper = *req.Copper
 		}
 		if req.Silver != nil {
 			currency.Silver = *req.Silver
 		}
+		if req.Electrum != nil {
+			currency.Electrum = *req.Electrum
+		}
 		if req.Gold != nil {
 			currency.Gold = *req.Gold
 		}
@@ -464,53 +556,74 @@ func (h *Handler) UpdateCurrency(c *gin.Context) {
 			currency.Platinum = *req.Platinum
 		}
 
-		if _, err := currencyRef.Set(ctx, currency); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando currency"})
-			return
+		if err := validateCurrency(currency); err != nil {
+			return err
+		}
+		if currency == before {
+			return nil
+		}
+
+		if err := tx.Set(currencyRef, currency); err != nil {
+			return err
 		}
+		_, err = h.ledgerEntryInTx(tx, &character, uid, reason, "", before, currency)
+		return err
+	})
 
-		c.JSON(http.StatusOK, currency)
+	if err != nil {
+		if errors.Is(err, errNegativeCurrency) || errors.Is(err, errCurrencyOverflow) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando currency"})
 		return
 	}
+	h.invalidateCharacterCache(ctx, characterID)
 
-	// Ya existe, actualizar
-	var currency models.Currency
-	currencyDoc.DataTo(&currency)
+	c.JSON(http.StatusOK, currency)
+}
+
+// ===========================
+// HELPERS DE INVENTARIO
+// ===========================
 
-	if req.Copper != nil {
-		currency.Copper = *req.Copper
+// characterEditableBy indica si uid es el dueño del personaje o el DM de su campaña
+// (los personajes del baúl no tienen campaña: solo el dueño)
+func (h *Handler) characterEditableBy(ctx context.Context, character models.Character, uid string) bool {
+	if character.UserID == uid {
+		return true
 	}
-	if req.Silver != nil {
-		currency.Silver = *req.Silver
+	if character.CampaignID == "" {
+		return false
 	}
-	if req.Gold != nil {
-		currency.Gold = *req.Gold
+	campaign, err := h.getCampaignByID(ctx, character.CampaignID)
+	return err == nil && campaign.DmID == uid
+}
+
+// itemOwnerInTx lee el personaje dueño de un item y verifica que uid sea su dueño o el DM de la campaña
+func (h *Handler) itemOwnerInTx(tx *firestore.Transaction, uid, charID string) (models.Character, error) {
+	var character models.Character
+	charDoc, err := tx.Get(h.db.Collection("characters").Doc(charID))
+	if err != nil {
+		return character, errVaultCharacterNotFound
 	}
-	if req.Platinum != nil {
-		currency.Platinum = *req.Platinum
+	if err := charDoc.DataTo(&character); err != nil {
+		return character, err
 	}
-
-	// Validar que no sean negativos
-	if currency.Copper < 0 || currency.Silver < 0 ||
-		currency.Gold < 0 || currency.Platinum < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "La moneda no puede ser negativa"})
-		return
+	if character.UserID == uid {
+		return character, nil
 	}
-
-	const MAX_CURRENCY = 999999999
-	if currency.Copper > MAX_CURRENCY || currency.Silver > MAX_CURRENCY ||
-		currency.Gold > MAX_CURRENCY || currency.Platinum > MAX_CURRENCY {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("El máximo de monedas por tipo es %d", MAX_CURRENCY),
-		})
-		return
+	if character.CampaignID == "" {
+		return character, errTransferForbidden
 	}
 
-	if _, err := currencyRef.Set(ctx, currency); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando currency"})
-		return
+	campaignDoc, err := tx.Get(h.db.Collection("events").Doc(character.CampaignID))
+	if err != nil {
+		return character, errTransferForbidden
 	}
-	h.invalidateCharacterCache(ctx, characterID) // 👈 AGREGAR
-
-	c.JSON(http.StatusOK, currency)
+	var campaign models.Campaign
+	if err := campaignDoc.DataTo(&campaign); err != nil || campaign.DmID != uid {
+		return character, errTransferForbidden
+	}
+	return character, nil
 }
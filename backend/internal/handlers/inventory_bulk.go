@@ -0,0 +1,428 @@
+// backend/internal/handlers/inventory_bulk.go
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// OPERACIONES EN LOTE E IMPORTACIÓN / EXPORTACIÓN
+// ===========================
+
+const (
+	MAX_BULK_ITEMS   = 200
+	MAX_BATCH_WRITES = 500 // Límite de escrituras de un batch de Firestore
+	maxImportBytes   = 1 << 20
+)
+
+var (
+	errBulkInvalid      = errors.New("lote inválido")
+	errBulkItemNotFound = errors.New("item no encontrado en el inventario")
+	errBulkFull         = fmt.Errorf("el personaje superaría el límite de %d items", MAX_ITEMS_PER_CHARACTER)
+)
+
+// inventoryCSVColumns - columnas del CSV de inventario (la importación acepta cualquier orden)
+var inventoryCSVColumns = []string{"name", "type", "quantity", "value", "weight", "volume", "slug", "description"}
+
+// BulkItems - Crear y eliminar varios items del personaje en un solo batch
+func (h *Handler) BulkItems(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.BulkItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Create) == 0 && len(req.Delete) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El lote está vacío"})
+		return
+	}
+
+	response, err := h.applyBulkItems(ctx, charID, c.GetString("uid"), req.Create, req.Delete)
+	if err != nil {
+		respondBulkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportInventory - Exportar el inventario del personaje (?format=csv|json)
+func (h *Handler) ExportInventory(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Formato inválido: usa csv o json"})
+		return
+	}
+
+	character, items, _, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+
+	filename := exportFilename(character.Name) + "-inventario"
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(inventoryCSVColumns)
+	for _, item := range items {
+		w.Write([]string{
+			csvSafe(item.Name),
+			string(item.Type),
+			strconv.Itoa(item.Quantity),
+			strconv.FormatFloat(float64(itemPriceCP(item))/100, 'f', -1, 64),
+			strconv.FormatFloat(item.Weight, 'f', -1, 64),
+			strconv.FormatFloat(item.Volume, 'f', -1, 64),
+			csvSafe(item.Open5eSlug),
+			csvSafe(item.Description),
+		})
+	}
+	w.Flush()
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(b.String()))
+}
+
+// ImportInventory - Agregar al inventario los items de un CSV o JSON (?format=csv|json,
+// por defecto según Content-Type). El JSON acepta la lista que devuelve ExportInventory.
+// Los items importados quedan sueltos (fuera de contenedores) y sin equipar.
+func (h *Handler) ImportInventory(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = "json"
+		if strings.Contains(c.ContentType(), "csv") {
+			format = "csv"
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var creates []models.CreateItemRequest
+	switch format {
+	case "json":
+		if err := c.ShouldBindJSON(&creates); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case "csv":
+		var err error
+		creates, err = parseInventoryCSV(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Formato inválido: usa csv o json"})
+		return
+	}
+
+	if len(creates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No hay items para importar"})
+		return
+	}
+	if len(creates) > MAX_BULK_ITEMS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Máximo %d items por importación", MAX_BULK_ITEMS)})
+		return
+	}
+	for i := range creates {
+		creates[i].ContainerID = ""
+	}
+
+	response, err := h.applyBulkItems(ctx, charID, c.GetString("uid"), creates, nil)
+	if err != nil {
+		respondBulkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ===========================
+// HELPERS DE OPERACIONES EN LOTE
+// ===========================
+
+// applyBulkItems valida todo el lote contra una sola lectura del inventario y lo
+// escribe en un único batch: o se aplica completo o no se aplica nada. Los items
+// nuevos se apilan como en CreateItem.
+func (h *Handler) applyBulkItems(ctx context.Context, charID, uid string, creates []models.CreateItemRequest, deletes []string) (models.BulkItemsResponse, error) {
+	response := models.BulkItemsResponse{Items: []models.InventoryItem{}, Deleted: []string{}}
+
+	character, items, _, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		return response, errVaultCharacterNotFound
+	}
+
+	// El homebrew de la campaña se lee una sola vez para todo el lote
+	homebrew := []models.HomebrewEntry{}
+	if character.CampaignID != "" && hasSlugs(creates) {
+		if homebrew, err = h.campaignHomebrew(ctx, character.CampaignID, models.HomebrewKindItem); err != nil {
+			return response, err
+		}
+	}
+
+	// Validar los items nuevos antes de tocar nada
+	newItems := make([]models.InventoryItem, 0, len(creates))
+	for i, req := range creates {
+		if err := applyCompendiumEntry(homebrew, &req); err != nil {
+			return response, fmt.Errorf("%w: create[%d]: %v", errBulkInvalid, i, err)
+		}
+		item := newItemFromRequest(req)
+		if err := validateItem(&item); err != nil {
+			return response, fmt.Errorf("%w: create[%d]: %v", errBulkInvalid, i, err)
+		}
+		newItems = append(newItems, item)
+	}
+
+	// Eliminaciones: los contenidos de un contenedor eliminado quedan sueltos
+	deleteSet := map[string]bool{}
+	for _, id := range deletes {
+		deleteSet[id] = true
+	}
+	remaining := []models.InventoryItem{}
+	armorRemoved := false
+	for _, item := range items {
+		if deleteSet[item.ID] {
+			delete(deleteSet, item.ID)
+			response.Deleted = append(response.Deleted, item.ID)
+			armorRemoved = armorRemoved || (item.Equipped && item.ArmorData != nil)
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+	for id := range deleteSet {
+		return response, fmt.Errorf("%w: %s", errBulkItemNotFound, id)
+	}
+
+	deleted := map[string]bool{}
+	for _, id := range response.Deleted {
+		deleted[id] = true
+	}
+	touched := []int{}
+	isTouched := map[int]bool{}
+	touch := func(index int) {
+		if !isTouched[index] {
+			isTouched[index] = true
+			touched = append(touched, index)
+		}
+	}
+	for i := range remaining {
+		if remaining[i].ContainerID != "" && deleted[remaining[i].ContainerID] {
+			remaining[i].ContainerID = ""
+			touch(i)
+		}
+	}
+
+	// Creaciones: apilar con lo existente o agregar respetando el límite
+	created := map[int]bool{}
+	added := map[int]bool{} // Items nuevos o pilas incrementadas (lo que se devuelve)
+	for i, item := range newItems {
+		if item.ContainerID != "" {
+			if err := validateContainerPlacement(remaining, item, item.ContainerID); err != nil {
+				return response, fmt.Errorf("%w: create[%d]: %v", errBulkInvalid, i, err)
+			}
+		}
+
+		if stack := findStack(remaining, item, item.Quantity); stack >= 0 {
+			remaining[stack].Quantity += item.Quantity
+			added[stack] = true
+			touch(stack)
+			continue
+		}
+
+		if len(remaining) >= MAX_ITEMS_PER_CHARACTER {
+			return response, errBulkFull
+		}
+		item.ID = h.db.Collection("inventory_items").NewDoc().ID
+		item.CharacterID = charID
+		item.CampaignID = character.CampaignID
+		remaining = append(remaining, item)
+		created[len(remaining)-1] = true
+		added[len(remaining)-1] = true
+		touch(len(remaining) - 1)
+	}
+
+	if len(response.Deleted)+len(touched) > MAX_BATCH_WRITES {
+		return response, fmt.Errorf("%w: demasiados cambios para un solo lote", errBulkInvalid)
+	}
+
+	now := time.Now()
+	batch := h.db.Batch()
+	for _, id := range response.Deleted {
+		batch.Delete(h.db.Collection("inventory_items").Doc(id))
+	}
+	for _, index := range touched {
+		item := &remaining[index]
+		if created[index] {
+			item.CreatedAt = now
+		}
+		item.UpdatedAt = now
+		batch.Set(h.db.Collection("inventory_items").Doc(item.ID), *item)
+		if added[index] {
+			response.Items = append(response.Items, *item)
+		}
+	}
+	if len(response.Deleted) > 0 || len(touched) > 0 {
+		if _, err := batch.Commit(ctx); err != nil {
+			return response, err
+		}
+	}
+
+	if armorRemoved {
+		h.refreshArmorClass(ctx, charID, uid)
+	}
+	h.invalidateCharacterCache(ctx, charID)
+
+	response.ItemCount = len(remaining)
+	return response, nil
+}
+
+// parseInventoryCSV convierte un CSV con encabezado (ver inventoryCSVColumns) en requests.
+// Cada fila necesita name y type, o un slug del compendio.
+func parseInventoryCSV(r io.Reader) ([]models.CreateItemRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %v", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		if _, ok := columns["slug"]; !ok {
+			return nil, errors.New("CSV inválido: falta la columna name o slug")
+		}
+	}
+
+	requests := []models.CreateItemRequest{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fila %d: %v", row, err)
+		}
+		if len(requests) >= MAX_BULK_ITEMS {
+			return nil, fmt.Errorf("máximo %d items por importación", MAX_BULK_ITEMS)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) (float64, error) {
+			raw := field(name)
+			if raw == "" {
+				return 0, nil
+			}
+			value, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+			if err != nil || value < 0 {
+				return 0, fmt.Errorf("fila %d: %s inválido", row, name)
+			}
+			return value, nil
+		}
+
+		req := models.CreateItemRequest{
+			Name:        csvUnescape(field("name")),
+			Type:        field("type"),
+			Description: csvUnescape(field("description")),
+			Open5eSlug:  csvUnescape(field("slug")),
+		}
+		if req.Name == "" && req.Open5eSlug == "" {
+			continue // Fila vacía
+		}
+		if req.Open5eSlug == "" && req.Type == "" {
+			return nil, fmt.Errorf("fila %d: falta el tipo", row)
+		}
+		if raw := field("quantity"); raw != "" {
+			quantity, err := strconv.Atoi(raw)
+			if err != nil || quantity < 0 || quantity > 999 {
+				return nil, fmt.Errorf("fila %d: quantity inválido", row)
+			}
+			req.Quantity = quantity
+		}
+		if req.Value, err = number("value"); err != nil {
+			return nil, err
+		}
+		if req.Weight, err = number("weight"); err != nil {
+			return nil, err
+		}
+		if req.Volume, err = number("volume"); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// csvSafe antepone ' a las celdas que una hoja de cálculo interpretaría como fórmula
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// csvUnescape deshace csvSafe para que exportar e importar no altere los textos
+func csvUnescape(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune("=+-@", rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// hasSlugs indica si alguna request del lote usa el compendio
+func hasSlugs(creates []models.CreateItemRequest) bool {
+	for _, req := range creates {
+		if req.Open5eSlug != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func respondBulkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errVaultCharacterNotFound), errors.Is(err, errBulkItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errBulkInvalid), errors.Is(err, errBulkFull):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error aplicando el lote"})
+	}
+}
@@ -0,0 +1,270 @@
+// backend/internal/handlers/item_validation.go
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// VALIDACIÓN DE ITEMS
+// ===========================
+
+var errInvalidItem = errors.New("item inválido")
+
+var validItemTypes = map[models.ItemType]bool{
+	models.ItemTypeWeapon: true, models.ItemTypeArmor: true, models.ItemTypeShield: true,
+	models.ItemTypeTool: true, models.ItemTypeConsumable: true, models.ItemTypeTreasure: true,
+	models.ItemTypeContainer: true, models.ItemTypeOther: true,
+}
+
+var validDamageTypes = map[string]bool{
+	"acid": true, "bludgeoning": true, "cold": true, "fire": true, "force": true,
+	"lightning": true, "necrotic": true, "piercing": true, "poison": true,
+	"psychic": true, "radiant": true, "slashing": true, "thunder": true,
+}
+
+// MAX_ITEM_PRICE_CP - precio máximo por unidad (999.999 po)
+const MAX_ITEM_PRICE_CP = 99999900
+
+// MAX_ITEM_QUANTITY - unidades máximas por pila; al superarlo se abre una pila nueva
+const MAX_ITEM_QUANTITY = 999
+
+var validDexModifiers = map[string]bool{"": true, "full": true, "max2": true, "none": true}
+
+// newItemFromRequest arma un item (sin ID ni dueño) con los datos del request
+func newItemFromRequest(req models.CreateItemRequest) models.InventoryItem {
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	item := models.InventoryItem{
+		Name:          req.Name,
+		Type:          models.ItemType(req.Type),
+		Description:   req.Description,
+		Quantity:      req.Quantity,
+		PriceCP:       gpToCP(req.Value),
+		Value:         req.Value,
+		Weight:        req.Weight,
+		Volume:        req.Volume,
+		ContainerID:   req.ContainerID,
+		WeaponData:    req.WeaponData,
+		ArmorData:     req.ArmorData,
+		ContainerData: req.ContainerData,
+		MagicData:     req.MagicData,
+		Effect:        req.Effect,
+		Open5eSlug:    req.Open5eSlug,
+	}
+	if req.Price != nil {
+		item.PriceCP = req.Price.Amount * denominationValue(req.Price.Denomination)
+		item.Denomination = req.Price.Denomination
+		item.Value = float64(item.PriceCP) / 100
+	}
+	return item
+}
+
+// normalizeItemPrice deja el precio en pc como dato principal: completa PriceCP en
+// items que solo traen value (po), elige la moneda si falta y recalcula value
+func normalizeItemPrice(item *models.InventoryItem) {
+	item.PriceCP = itemPriceCP(*item)
+	if denominationValue(item.Denomination) == 0 {
+		item.Denomination = priceDenomination(item.PriceCP)
+	}
+	item.Value = float64(item.PriceCP) / 100
+}
+
+// validateItem revisa los datos del item según su tipo y normaliza nombre y tipo de daño
+func validateItem(item *models.InventoryItem) error {
+	item.Name = strings.TrimSpace(item.Name)
+	if item.Name == "" || len(item.Name) > 100 {
+		return fmt.Errorf("%w: el nombre debe tener entre 1 y 100 caracteres", errInvalidItem)
+	}
+	if len(item.Description) > 1000 {
+		return fmt.Errorf("%w: la descripción admite hasta 1000 caracteres", errInvalidItem)
+	}
+	if !validItemTypes[item.Type] {
+		return fmt.Errorf("%w: tipo desconocido %q", errInvalidItem, item.Type)
+	}
+	if item.Quantity < 1 || item.Quantity > MAX_ITEM_QUANTITY {
+		return fmt.Errorf("%w: la cantidad debe estar entre 1 y %d", errInvalidItem, MAX_ITEM_QUANTITY)
+	}
+	normalizeItemPrice(item)
+	if item.PriceCP < 0 || item.PriceCP > MAX_ITEM_PRICE_CP || item.Weight < 0 || item.Weight > 9999 || item.Volume < 0 || item.Volume > 9999 {
+		return fmt.Errorf("%w: valor, peso o volumen fuera de rango", errInvalidItem)
+	}
+
+	if item.WeaponData != nil {
+		if item.Type != models.ItemTypeWeapon {
+			return fmt.Errorf("%w: solo las armas pueden tener datos de arma", errInvalidItem)
+		}
+		if err := validateWeaponData(item.WeaponData); err != nil {
+			return err
+		}
+	}
+
+	if item.ArmorData != nil {
+		if item.Type != models.ItemTypeArmor && item.Type != models.ItemTypeShield {
+			return fmt.Errorf("%w: solo armaduras y escudos pueden tener datos de armadura", errInvalidItem)
+		}
+		if err := validateArmorData(*item); err != nil {
+			return err
+		}
+	}
+
+	if item.MagicData != nil {
+		if err := validateMagicData(item.MagicData); err != nil {
+			return err
+		}
+	}
+
+	if item.Effect != nil {
+		if item.Type != models.ItemTypeConsumable {
+			return fmt.Errorf("%w: solo los consumibles pueden tener efectos", errInvalidItem)
+		}
+		if err := validateConsumableEffect(item.Effect); err != nil {
+			return err
+		}
+	}
+
+	if item.ContainerData != nil {
+		if item.Type != models.ItemTypeContainer {
+			return fmt.Errorf("%w: solo los contenedores pueden tener capacidad", errInvalidItem)
+		}
+		data := item.ContainerData
+		if data.CapacityWeight < 0 || data.CapacityVolume < 0 {
+			return fmt.Errorf("%w: la capacidad no puede ser negativa", errInvalidItem)
+		}
+	}
+
+	return nil
+}
+
+func validateWeaponData(weapon *models.WeaponData) error {
+	category := strings.ToLower(weapon.WeaponType)
+	if !strings.Contains(category, "simple") && !strings.Contains(category, "martial") {
+		return fmt.Errorf("%w: el tipo de arma debe ser simple o marcial", errInvalidItem)
+	}
+
+	// Algunas armas (red, cerbatana sin munición) no tienen daño propio
+	if weapon.DamageDice != "" {
+		if _, err := parseDice(weapon.DamageDice); err != nil {
+			return fmt.Errorf("%w: daño: %v", errInvalidItem, err)
+		}
+	}
+	if weapon.Properties.Versatile != "" {
+		if _, err := parseDice(weapon.Properties.Versatile); err != nil {
+			return fmt.Errorf("%w: daño versátil: %v", errInvalidItem, err)
+		}
+	}
+
+	weapon.DamageType = strings.ToLower(strings.TrimSpace(weapon.DamageType))
+	if weapon.DamageType != "" && !validDamageTypes[weapon.DamageType] {
+		return fmt.Errorf("%w: tipo de daño desconocido %q", errInvalidItem, weapon.DamageType)
+	}
+
+	if r := weapon.Properties.Range; r != nil && (r.Normal < 0 || r.Max < r.Normal) {
+		return fmt.Errorf("%w: alcance inválido (%d/%d)", errInvalidItem, r.Normal, r.Max)
+	}
+	if weapon.MagicBonus < -5 || weapon.MagicBonus > 10 {
+		return fmt.Errorf("%w: bonificador mágico fuera de rango", errInvalidItem)
+	}
+	return nil
+}
+
+func validateArmorData(item models.InventoryItem) error {
+	data := item.ArmorData
+	switch armorCategory(item) {
+	case "light", "medium", "heavy", "shield":
+	default:
+		return fmt.Errorf("%w: tipo de armadura desconocido %q (Light, Medium, Heavy o Shield)", errInvalidItem, data.ArmorType)
+	}
+
+	if !validDexModifiers[data.DexModifier] {
+		return fmt.Errorf("%w: modificador de destreza desconocido %q (full, max2 o none)", errInvalidItem, data.DexModifier)
+	}
+	if data.BaseAC < 0 || data.BaseAC > 30 {
+		return fmt.Errorf("%w: CA base fuera de rango", errInvalidItem)
+	}
+	if data.StrengthRequirement < 0 || data.StrengthRequirement > 30 {
+		return fmt.Errorf("%w: requisito de fuerza fuera de rango", errInvalidItem)
+	}
+	if data.MagicBonus < -5 || data.MagicBonus > 10 {
+		return fmt.Errorf("%w: bonificador mágico fuera de rango", errInvalidItem)
+	}
+	return nil
+}
+
+var validRarities = map[string]bool{
+	models.RarityCommon: true, models.RarityUncommon: true, models.RarityRare: true,
+	models.RarityVeryRare: true, models.RarityLegendary: true, models.RarityArtifact: true,
+}
+
+var validChargeRecharges = map[string]bool{
+	models.RechargeDawn: true, models.RechargeShortRest: true, models.RechargeLongRest: true, models.RechargeNone: true,
+}
+
+func validateMagicData(magic *models.MagicItemData) error {
+	magic.Rarity = strings.ToLower(strings.TrimSpace(magic.Rarity))
+	if magic.Rarity == "" {
+		magic.Rarity = models.RarityCommon
+	}
+	if !validRarities[magic.Rarity] {
+		return fmt.Errorf("%w: rareza desconocida %q", errInvalidItem, magic.Rarity)
+	}
+	if len(magic.AttunementBy) > 0 && !magic.RequiresAttunement {
+		return fmt.Errorf("%w: attunementBy solo aplica a objetos que requieren sintonía", errInvalidItem)
+	}
+	if len(magic.AttunementBy) > 20 || len(magic.Spells) > 50 {
+		return fmt.Errorf("%w: demasiados requisitos de sintonía o conjuros", errInvalidItem)
+	}
+
+	if charges := magic.Charges; charges != nil {
+		if charges.Max < 1 || charges.Max > 100 || charges.Current < 0 || charges.Current > charges.Max {
+			return fmt.Errorf("%w: cargas fuera de rango (%d/%d)", errInvalidItem, charges.Current, charges.Max)
+		}
+		if charges.Recharge == "" {
+			charges.Recharge = models.RechargeDawn
+		}
+		if !validChargeRecharges[charges.Recharge] {
+			return fmt.Errorf("%w: recarga desconocida %q (dawn, short, long o none)", errInvalidItem, charges.Recharge)
+		}
+		if charges.RechargeFormula != "" {
+			if _, err := parseDice(charges.RechargeFormula); err != nil {
+				return fmt.Errorf("%w: fórmula de recarga: %v", errInvalidItem, err)
+			}
+		}
+	}
+
+	for _, spell := range magic.Spells {
+		if strings.TrimSpace(spell.Name) == "" || len(spell.Name) > 100 {
+			return fmt.Errorf("%w: cada conjuro necesita un nombre", errInvalidItem)
+		}
+		if spell.Level < 0 || spell.Level > 9 || spell.ChargeCost < 0 || spell.SaveDC < 0 || spell.SaveDC > 30 {
+			return fmt.Errorf("%w: datos inválidos en el conjuro %q", errInvalidItem, spell.Name)
+		}
+		if spell.ChargeCost > 0 && magic.Charges == nil {
+			return fmt.Errorf("%w: el conjuro %q gasta cargas pero el objeto no tiene", errInvalidItem, spell.Name)
+		}
+	}
+	return nil
+}
+
+func validateConsumableEffect(effect *models.ConsumableEffect) error {
+	if effect.HealDice == "" && effect.TemporaryHPDice == "" && len(effect.RemoveConditions) == 0 {
+		return fmt.Errorf("%w: el efecto no hace nada", errInvalidItem)
+	}
+	for _, formula := range []string{effect.HealDice, effect.TemporaryHPDice} {
+		if formula == "" {
+			continue
+		}
+		if _, err := parseDice(formula); err != nil {
+			return fmt.Errorf("%w: efecto: %v", errInvalidItem, err)
+		}
+	}
+	if len(effect.RemoveConditions) > 20 {
+		return fmt.Errorf("%w: demasiadas condiciones", errInvalidItem)
+	}
+	return nil
+}
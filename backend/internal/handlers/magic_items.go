@@ -0,0 +1,234 @@
+// backend/internal/handlers/magic_items.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// OBJETOS MÁGICOS: CARGAS Y SINTONÍA
+// ===========================
+
+var (
+	errItemHasNoCharges       = errors.New("el objeto no tiene cargas")
+	errNotEnoughCharges       = errors.New("no quedan cargas suficientes")
+	errItemSpellNotFound      = errors.New("el objeto no permite lanzar ese conjuro")
+	errItemNotAttuned         = errors.New("hay que sintonizar el objeto para usar sus cargas")
+	errAttunementNotRequired  = errors.New("el objeto no requiere sintonía")
+	errAttunementNotPermitted = errors.New("el personaje no cumple los requisitos de sintonía")
+)
+
+// classAliases - nombres de clase en inglés y español
+var classAliases = map[string]string{
+	"barbarian": "bárbaro", "bard": "bardo", "cleric": "clérigo", "druid": "druida",
+	"fighter": "guerrero", "monk": "monje", "paladin": "paladín", "ranger": "explorador",
+	"rogue": "pícaro", "sorcerer": "hechicero", "warlock": "brujo", "wizard": "mago",
+	"artificer": "artífice",
+}
+
+// SpendItemCharges - Gastar cargas de un objeto (una cantidad o el costo de uno de sus conjuros)
+func (h *Handler) SpendItemCharges(c *gin.Context) {
+	h.changeItemCharges(c, true)
+}
+
+// RechargeItem - Recuperar cargas de un objeto (amount 0 = tirar la fórmula de recarga)
+func (h *Handler) RechargeItem(c *gin.Context) {
+	h.changeItemCharges(c, false)
+}
+
+func (h *Handler) changeItemCharges(c *gin.Context, spend bool) {
+	charID := c.Param("charId")
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	var req models.ItemChargesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itemRef := h.db.Collection("inventory_items").Doc(itemID)
+	var response models.ItemChargesResponse
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		response = models.ItemChargesResponse{}
+
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errEquipItemNotFound
+		}
+		var item models.InventoryItem
+		if err := itemDoc.DataTo(&item); err != nil {
+			return err
+		}
+		if item.CharacterID != charID {
+			return errEquipItemNotFound
+		}
+		if item.MagicData == nil || item.MagicData.Charges == nil {
+			return errItemHasNoCharges
+		}
+
+		magic := *item.MagicData
+		charges := *magic.Charges
+
+		if spend {
+			if magic.RequiresAttunement && !item.Attuned {
+				return errItemNotAttuned
+			}
+			amount := req.Amount
+			if req.Spell != "" {
+				spell, ok := findItemSpell(magic.Spells, req.Spell)
+				if !ok {
+					return errItemSpellNotFound
+				}
+				amount = spell.ChargeCost
+			} else if amount == 0 {
+				amount = 1
+			}
+			if charges.Current < amount {
+				return errNotEnoughCharges
+			}
+			charges.Current -= amount
+			response.Changed = amount
+		} else {
+			before := charges.Current
+			if req.Amount == 0 {
+				charges, response.Roll = rechargeCharges(charges)
+			} else {
+				charges.Current = minInt(charges.Current+req.Amount, charges.Max)
+			}
+			response.Changed = charges.Current - before
+		}
+
+		magic.Charges = &charges
+		item.MagicData = &magic
+		item.UpdatedAt = time.Now()
+		response.Item = item
+
+		return tx.Update(itemRef, []firestore.Update{
+			{Path: "magicData", Value: item.MagicData},
+			{Path: "updatedAt", Value: item.UpdatedAt},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errEquipItemNotFound), errors.Is(err, errItemSpellNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errItemHasNoCharges), errors.Is(err, errNotEnoughCharges), errors.Is(err, errItemNotAttuned):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando cargas"})
+		}
+		return
+	}
+
+	h.invalidateCharacterCache(ctx, charID)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ===========================
+// HELPERS DE OBJETOS MÁGICOS
+// ===========================
+
+func findItemSpell(spells []models.ItemSpell, name string) (models.ItemSpell, bool) {
+	for _, spell := range spells {
+		if strings.EqualFold(spell.Name, strings.TrimSpace(name)) {
+			return spell, true
+		}
+	}
+	return models.ItemSpell{}, false
+}
+
+// rechargeCharges aplica la fórmula de recarga ("1d6+1") o recarga todo si no tiene
+func rechargeCharges(charges models.ItemCharges) (models.ItemCharges, *models.DiceRoll) {
+	if charges.RechargeFormula == "" {
+		charges.Current = charges.Max
+		return charges, nil
+	}
+
+	expr, err := parseDice(charges.RechargeFormula)
+	if err != nil {
+		charges.Current = charges.Max
+		return charges, nil
+	}
+	roll := expr.roll(false)
+	charges.Current = minInt(charges.Current+roll.Total, charges.Max)
+	return charges, &roll
+}
+
+// rechargeItemsOnRest recarga los objetos según el descanso. Corto: "short".
+// Largo: "short", "long" y "dawn". Devuelve los items modificados y una descripción por item.
+func rechargeItemsOnRest(items []models.InventoryItem, restType string) ([]models.InventoryItem, []string) {
+	changed := []models.InventoryItem{}
+	recharged := []string{}
+
+	for _, item := range items {
+		if item.MagicData == nil || item.MagicData.Charges == nil {
+			continue
+		}
+		charges := *item.MagicData.Charges
+		if charges.Current >= charges.Max {
+			continue
+		}
+
+		switch {
+		case charges.Recharge == models.RechargeShortRest,
+			restType == models.RechargeLongRest && (charges.Recharge == models.RechargeLongRest || charges.Recharge == models.RechargeDawn):
+		default:
+			continue
+		}
+
+		before := charges.Current
+		charges, _ = rechargeCharges(charges)
+		magic := *item.MagicData
+		magic.Charges = &charges
+		item.MagicData = &magic
+
+		changed = append(changed, item)
+		recharged = append(recharged, fmt.Sprintf("%s (+%d cargas)", item.Name, charges.Current-before))
+	}
+
+	return changed, recharged
+}
+
+// checkAttunement valida los requisitos de sintonía del objeto para el personaje.
+// Los items sin datos mágicos se pueden sintonizar libremente (compatibilidad).
+func checkAttunement(char *models.Character, item models.InventoryItem) error {
+	magic := item.MagicData
+	if magic == nil {
+		return nil
+	}
+	if !magic.RequiresAttunement {
+		return errAttunementNotRequired
+	}
+	if len(magic.AttunementBy) == 0 {
+		return nil
+	}
+
+	class := strings.ToLower(strings.TrimSpace(char.Class))
+	race := strings.ToLower(strings.TrimSpace(char.Race))
+	for _, allowed := range magic.AttunementBy {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == class || allowed == race || classAliases[allowed] == class || classAliases[class] == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: solo %s", errAttunementNotPermitted, strings.Join(magic.AttunementBy, ", "))
+}
+
+// hasCharges indica si el item lleva un contador de cargas propio (no se apila)
+func hasCharges(magic *models.MagicItemData) bool {
+	return magic != nil && magic.Charges != nil
+}
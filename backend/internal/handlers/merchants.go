@@ -0,0 +1,740 @@
+// backend/internal/handlers/merchants.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// TIENDAS
+// ===========================
+
+const (
+	MAX_MERCHANT_STOCK = 200
+)
+
+var (
+	errMerchantNotFound  = errors.New("tienda no encontrada")
+	errMerchantClosed    = errors.New("la tienda está cerrada")
+	errStockNotFound     = errors.New("el item no está a la venta")
+	errStockFull         = fmt.Errorf("la tienda alcanzó el límite de %d items", MAX_MERCHANT_STOCK)
+	errNotEnoughStock    = errors.New("la tienda no tiene stock suficiente")
+	errTradeForbidden    = errors.New("solo puedes comerciar con tu personaje")
+	errTradeItemNotFound = errors.New("item no encontrado en el inventario")
+)
+
+// GetMerchants - Tiendas de la campaña (los jugadores solo ven las abiertas)
+func (h *Handler) GetMerchants(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	campaign, err := h.getCampaignByID(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+
+	iter := h.db.Collection("merchants").
+		Where("campaignId", "==", campaignID).
+		Documents(ctx)
+
+	merchants := []models.Merchant{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo tiendas"})
+			return
+		}
+
+		var merchant models.Merchant
+		if err := doc.DataTo(&merchant); err != nil {
+			continue
+		}
+		if !merchant.IsOpen && campaign.DmID != uid {
+			continue
+		}
+		if merchant.Stock == nil {
+			merchant.Stock = []models.MerchantStockItem{}
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	c.JSON(http.StatusOK, merchants)
+}
+
+// GetMerchant - Una tienda con su stock
+func (h *Handler) GetMerchant(c *gin.Context) {
+	uid := c.GetString("uid")
+	ctx := context.Background()
+
+	merchant, err := h.getMerchant(ctx, c.Param("id"), c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !merchant.IsOpen {
+		campaign, err := h.getCampaignByID(ctx, merchant.CampaignID)
+		if err != nil || campaign.DmID != uid {
+			c.JSON(http.StatusForbidden, gin.H{"error": errMerchantClosed.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// CreateMerchant - Crear una tienda (solo DM)
+func (h *Handler) CreateMerchant(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.CreateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	ref := h.db.Collection("merchants").NewDoc()
+	merchant := models.Merchant{
+		ID:           ref.ID,
+		CampaignID:   campaignID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Markup:       1.0,
+		SellBackRate: 0.5,
+		IsOpen:       req.IsOpen,
+		Stock:        []models.MerchantStockItem{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.Markup != nil {
+		merchant.Markup = *req.Markup
+	}
+	if req.SellBackRate != nil {
+		merchant.SellBackRate = *req.SellBackRate
+	}
+
+	if _, err := ref.Set(ctx, merchant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando tienda"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, merchant)
+}
+
+// UpdateMerchant - Editar nombre, precios o apertura de una tienda (solo DM)
+func (h *Handler) UpdateMerchant(c *gin.Context) {
+	ctx := context.Background()
+
+	var req models.UpdateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant, err := h.getMerchant(ctx, c.Param("id"), c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := []firestore.Update{
+		{Path: "updatedAt", Value: time.Now()},
+	}
+	if req.Name != nil {
+		merchant.Name = *req.Name
+		updates = append(updates, firestore.Update{Path: "name", Value: *req.Name})
+	}
+	if req.Description != nil {
+		merchant.Description = *req.Description
+		updates = append(updates, firestore.Update{Path: "description", Value: *req.Description})
+	}
+	if req.Markup != nil {
+		merchant.Markup = *req.Markup
+		updates = append(updates, firestore.Update{Path: "markup", Value: *req.Markup})
+	}
+	if req.SellBackRate != nil {
+		merchant.SellBackRate = *req.SellBackRate
+		updates = append(updates, firestore.Update{Path: "sellBackRate", Value: *req.SellBackRate})
+	}
+	if req.IsOpen != nil {
+		merchant.IsOpen = *req.IsOpen
+		updates = append(updates, firestore.Update{Path: "isOpen", Value: *req.IsOpen})
+	}
+
+	if _, err := h.db.Collection("merchants").Doc(merchant.ID).Update(ctx, updates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando tienda"})
+		return
+	}
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// DeleteMerchant - Eliminar una tienda (solo DM)
+func (h *Handler) DeleteMerchant(c *gin.Context) {
+	ctx := context.Background()
+
+	merchant, err := h.getMerchant(ctx, c.Param("id"), c.Param("merchantId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.db.Collection("merchants").Doc(merchant.ID).Delete(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando tienda"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tienda eliminada"})
+}
+
+// AddMerchantStock - Agregar items al stock (se apilan por Open5eSlug o nombre y tipo).
+// Con open5eSlug los datos faltantes se toman del compendio.
+func (h *Handler) AddMerchantStock(c *gin.Context) {
+	campaignID := c.Param("id")
+	merchantID := c.Param("merchantId")
+	ctx := context.Background()
+
+	var req models.AddStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Quantity == 0 && !req.Unlimited {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Indica una cantidad o marca el stock como ilimitado"})
+		return
+	}
+
+	// El stock sigue las mismas reglas que el inventario (se compra tal cual): se completa
+	// con el compendio (homebrew de la campaña incluido) y se valida como un item
+	itemReq := models.CreateItemRequest{
+		Name:          req.Name,
+		Type:          req.Type,
+		Description:   req.Description,
+		Value:         req.Value,
+		Price:         req.Price,
+		Weight:        req.Weight,
+		Volume:        req.Volume,
+		WeaponData:    req.WeaponData,
+		ArmorData:     req.ArmorData,
+		ContainerData: req.ContainerData,
+		MagicData:     req.MagicData,
+		Effect:        req.Effect,
+		Open5eSlug:    req.Open5eSlug,
+	}
+	if err := h.applyCompendium(ctx, campaignID, &itemReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item := newItemFromRequest(itemReq)
+	if err := validateItem(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry := models.MerchantStockItem{
+		ID:            generateID(),
+		Name:          item.Name,
+		Type:          item.Type,
+		Description:   item.Description,
+		PriceCP:       item.PriceCP,
+		Denomination:  item.Denomination,
+		Price:         item.Value,
+		Quantity:      req.Quantity,
+		Unlimited:     req.Unlimited,
+		Weight:        item.Weight,
+		Volume:        item.Volume,
+		WeaponData:    item.WeaponData,
+		ArmorData:     item.ArmorData,
+		ContainerData: item.ContainerData,
+		MagicData:     item.MagicData,
+		Effect:        item.Effect,
+		Open5eSlug:    item.Open5eSlug,
+	}
+
+	ref := h.db.Collection("merchants").Doc(merchantID)
+	var merchant models.Merchant
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return errMerchantNotFound
+		}
+		if err := doc.DataTo(&merchant); err != nil || merchant.CampaignID != campaignID {
+			return errMerchantNotFound
+		}
+
+		stock, err := addToStock(merchant.Stock, entry)
+		if err != nil {
+			return err
+		}
+		merchant.Stock = stock
+		merchant.UpdatedAt = time.Now()
+
+		return tx.Update(ref, []firestore.Update{
+			{Path: "stock", Value: merchant.Stock},
+			{Path: "updatedAt", Value: merchant.UpdatedAt},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errMerchantNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errStockFull):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error agregando stock"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// RemoveMerchantStock - Quitar un item del stock
+func (h *Handler) RemoveMerchantStock(c *gin.Context) {
+	campaignID := c.Param("id")
+	merchantID := c.Param("merchantId")
+	stockID := c.Param("stockId")
+	ctx := context.Background()
+
+	ref := h.db.Collection("merchants").Doc(merchantID)
+	var merchant models.Merchant
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return errMerchantNotFound
+		}
+		if err := doc.DataTo(&merchant); err != nil || merchant.CampaignID != campaignID {
+			return errMerchantNotFound
+		}
+
+		stock := make([]models.MerchantStockItem, 0, len(merchant.Stock))
+		for _, s := range merchant.Stock {
+			if s.ID != stockID {
+				stock = append(stock, s)
+			}
+		}
+		if len(stock) == len(merchant.Stock) {
+			return errStockNotFound
+		}
+		merchant.Stock = stock
+		merchant.UpdatedAt = time.Now()
+
+		return tx.Update(ref, []firestore.Update{
+			{Path: "stock", Value: merchant.Stock},
+			{Path: "updatedAt", Value: merchant.UpdatedAt},
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, errMerchantNotFound) || errors.Is(err, errStockNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error quitando stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, merchant)
+}
+
+// BuyFromMerchant - Comprar: descuenta monedas (con cambio), resta stock y agrega el item al inventario
+func (h *Handler) BuyFromMerchant(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	merchantID := c.Param("merchantId")
+	ctx := context.Background()
+
+	var req models.MerchantTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantRef := h.db.Collection("merchants").Doc(merchantID)
+	currencyRef := h.db.Collection("currencies").Doc(req.CharacterID)
+	var response models.MerchantTradeResponse
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		merchant, character, isDM, err := h.tradeParties(tx, uid, campaignID, merchantRef, req.CharacterID)
+		if err != nil {
+			return err
+		}
+		if !merchant.IsOpen && !isDM {
+			return errMerchantClosed
+		}
+
+		index := -1
+		for i, s := range merchant.Stock {
+			if s.ID == req.ItemID {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return errStockNotFound
+		}
+		entry := merchant.Stock[index]
+		if !entry.Unlimited && entry.Quantity < req.Quantity {
+			return errNotEnoughStock
+		}
+
+		balance, err := currencyInTx(tx, currencyRef)
+		if err != nil {
+			return err
+		}
+		items, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", character.ID))
+		if err != nil {
+			return err
+		}
+
+		// Cobro
+		priceCP := stockPriceCP(entry)
+		costCP := int(math.Round(float64(priceCP)*merchant.Markup)) * req.Quantity
+		updated := balance
+		if costCP > 0 {
+			if updated, err = payWithChange(balance, "cp", costCP); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		bought := models.InventoryItem{
+			CharacterID:   character.ID,
+			CampaignID:    campaignID,
+			Name:          entry.Name,
+			Type:          entry.Type,
+			Description:   entry.Description,
+			Quantity:      req.Quantity,
+			PriceCP:       priceCP,
+			Denomination:  entry.Denomination,
+			Value:         float64(priceCP) / 100,
+			Weight:        entry.Weight,
+			Volume:        entry.Volume,
+			WeaponData:    entry.WeaponData,
+			ArmorData:     entry.ArmorData,
+			ContainerData: entry.ContainerData,
+			MagicData:     entry.MagicData,
+			Effect:        entry.Effect,
+			Open5eSlug:    entry.Open5eSlug,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+
+		stack := findStack(items, bought, bought.Quantity)
+		if stack == -1 && len(items) >= MAX_ITEMS_PER_CHARACTER {
+			return errTransferTargetFull
+		}
+
+		// Stock
+		if !entry.Unlimited {
+			merchant.Stock[index].Quantity -= req.Quantity
+			if merchant.Stock[index].Quantity == 0 {
+				merchant.Stock = append(merchant.Stock[:index], merchant.Stock[index+1:]...)
+			}
+		}
+
+		if err := tx.Update(merchantRef, []firestore.Update{
+			{Path: "stock", Value: merchant.Stock},
+			{Path: "updatedAt", Value: now},
+		}); err != nil {
+			return err
+		}
+		if err := tx.Set(currencyRef, updated); err != nil {
+			return err
+		}
+
+		if stack != -1 {
+			bought = items[stack]
+			bought.Quantity += req.Quantity
+			bought.UpdatedAt = now
+			if err := tx.Update(h.db.Collection("inventory_items").Doc(bought.ID), []firestore.Update{
+				{Path: "quantity", Value: bought.Quantity},
+				{Path: "updatedAt", Value: now},
+			}); err != nil {
+				return err
+			}
+		} else {
+			itemRef := h.db.Collection("inventory_items").NewDoc()
+			bought.ID = itemRef.ID
+			if err := tx.Set(itemRef, bought); err != nil {
+				return err
+			}
+		}
+
+		reason := fmt.Sprintf("Compra en %s: %d x %s", merchant.Name, req.Quantity, entry.Name)
+		if _, err := h.ledgerEntryInTx(tx, character, uid, reason, "", balance, updated); err != nil {
+			return err
+		}
+
+		response = models.MerchantTradeResponse{
+			Item:     bought,
+			Total:    newWorth(int64(costCP)),
+			Currency: updated,
+		}
+		return nil
+	})
+
+	if err != nil {
+		h.respondTradeError(c, err, "Error realizando la compra")
+		return
+	}
+
+	h.invalidateCharacterCache(ctx, req.CharacterID)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SellToMerchant - Vender: el item pasa al stock de la tienda y el personaje recibe Value x SellBackRate
+func (h *Handler) SellToMerchant(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	merchantID := c.Param("merchantId")
+	ctx := context.Background()
+
+	var req models.MerchantTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchantRef := h.db.Collection("merchants").Doc(merchantID)
+	currencyRef := h.db.Collection("currencies").Doc(req.CharacterID)
+	itemRef := h.db.Collection("inventory_items").Doc(req.ItemID)
+	var response models.MerchantTradeResponse
+	var sold models.InventoryItem
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		merchant, character, isDM, err := h.tradeParties(tx, uid, campaignID, merchantRef, req.CharacterID)
+		if err != nil {
+			return err
+		}
+		if !merchant.IsOpen && !isDM {
+			return errMerchantClosed
+		}
+
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errTradeItemNotFound
+		}
+		if err := itemDoc.DataTo(&sold); err != nil || sold.CharacterID != character.ID {
+			return errTradeItemNotFound
+		}
+		if sold.Quantity < req.Quantity {
+			return errTransferNotEnough
+		}
+		if err := containerIsEmpty(tx, h.db, sold); err != nil {
+			return err
+		}
+
+		balance, err := currencyInTx(tx, currencyRef)
+		if err != nil {
+			return err
+		}
+
+		stock, err := addToStock(merchant.Stock, models.MerchantStockItem{
+			ID:            generateID(),
+			Name:          sold.Name,
+			Type:          sold.Type,
+			Description:   sold.Description,
+			PriceCP:       itemPriceCP(sold),
+			Denomination:  sold.Denomination,
+			Price:         float64(itemPriceCP(sold)) / 100,
+			Quantity:      req.Quantity,
+			Weight:        sold.Weight,
+			Volume:        sold.Volume,
+			WeaponData:    sold.WeaponData,
+			ArmorData:     sold.ArmorData,
+			ContainerData: sold.ContainerData,
+			MagicData:     sold.MagicData,
+			Effect:        sold.Effect,
+			Open5eSlug:    sold.Open5eSlug,
+		})
+		if err != nil {
+			return err
+		}
+
+		earnedCP := int(math.Round(float64(itemPriceCP(sold))*merchant.SellBackRate)) * req.Quantity
+		updated := addCurrency(balance, currencyFromCP(earnedCP), 1)
+		if err := validateCurrency(updated); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if sold.Quantity == req.Quantity {
+			if err := tx.Delete(itemRef); err != nil {
+				return err
+			}
+		} else if err := tx.Update(itemRef, []firestore.Update{
+			{Path: "quantity", Value: sold.Quantity - req.Quantity},
+			{Path: "updatedAt", Value: now},
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Update(merchantRef, []firestore.Update{
+			{Path: "stock", Value: stock},
+			{Path: "updatedAt", Value: now},
+		}); err != nil {
+			return err
+		}
+		if err := tx.Set(currencyRef, updated); err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("Venta en %s: %d x %s", merchant.Name, req.Quantity, sold.Name)
+		if _, err := h.ledgerEntryInTx(tx, character, uid, reason, "", balance, updated); err != nil {
+			return err
+		}
+
+		item := sold
+		item.Quantity = sold.Quantity - req.Quantity
+		response = models.MerchantTradeResponse{
+			Item:     item,
+			Total:    newWorth(int64(earnedCP)),
+			Currency: updated,
+		}
+		return nil
+	})
+
+	if err != nil {
+		h.respondTradeError(c, err, "Error realizando la venta")
+		return
+	}
+
+	if sold.Equipped && sold.ArmorData != nil && sold.Quantity == req.Quantity {
+		h.refreshArmorClass(ctx, req.CharacterID, uid)
+	}
+	h.invalidateCharacterCache(ctx, req.CharacterID)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ===========================
+// HELPERS DE TIENDAS
+// ===========================
+
+func (h *Handler) getMerchant(ctx context.Context, campaignID, merchantID string) (*models.Merchant, error) {
+	doc, err := h.db.Collection("merchants").Doc(merchantID).Get(ctx)
+	if err != nil {
+		return nil, errMerchantNotFound
+	}
+
+	var merchant models.Merchant
+	if err := doc.DataTo(&merchant); err != nil || merchant.CampaignID != campaignID {
+		return nil, errMerchantNotFound
+	}
+	if merchant.Stock == nil {
+		merchant.Stock = []models.MerchantStockItem{}
+	}
+
+	return &merchant, nil
+}
+
+// tradeParties lee la tienda y el personaje, y valida que el usuario sea su dueño o el DM
+func (h *Handler) tradeParties(tx *firestore.Transaction, uid, campaignID string, merchantRef *firestore.DocumentRef, charID string) (*models.Merchant, *models.Character, bool, error) {
+	merchantDoc, err := tx.Get(merchantRef)
+	if err != nil {
+		return nil, nil, false, errMerchantNotFound
+	}
+	var merchant models.Merchant
+	if err := merchantDoc.DataTo(&merchant); err != nil || merchant.CampaignID != campaignID {
+		return nil, nil, false, errMerchantNotFound
+	}
+
+	charDoc, err := tx.Get(h.db.Collection("characters").Doc(charID))
+	if err != nil {
+		return nil, nil, false, errVaultCharacterNotFound
+	}
+	var character models.Character
+	if err := charDoc.DataTo(&character); err != nil {
+		return nil, nil, false, err
+	}
+	if character.CampaignID != campaignID {
+		return nil, nil, false, errTransferOtherCampaign
+	}
+
+	campaignDoc, err := tx.Get(h.db.Collection("events").Doc(campaignID))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var campaign models.Campaign
+	if err := campaignDoc.DataTo(&campaign); err != nil {
+		return nil, nil, false, err
+	}
+
+	isDM := campaign.DmID == uid
+	if character.UserID != uid && !isDM {
+		return nil, nil, false, errTradeForbidden
+	}
+
+	return &merchant, &character, isDM, nil
+}
+
+func (h *Handler) respondTradeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errMerchantNotFound), errors.Is(err, errStockNotFound),
+		errors.Is(err, errTradeItemNotFound), errors.Is(err, errVaultCharacterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errTradeForbidden), errors.Is(err, errMerchantClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, errNotEnoughStock), errors.Is(err, errInsufficientFunds), errors.Is(err, errTransferNotEnough),
+		errors.Is(err, errTransferOtherCampaign), errors.Is(err, errTransferTargetFull),
+		errors.Is(err, errStockFull), errors.Is(err, errCurrencyOverflow), errors.Is(err, errContainerNotEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// stockPriceCP - precio por unidad en pc (el stock anterior solo tiene price en po)
+func stockPriceCP(entry models.MerchantStockItem) int64 {
+	if entry.PriceCP > 0 {
+		return entry.PriceCP
+	}
+	return gpToCP(entry.Price)
+}
+
+// addToStock apila la entrada en el stock (mismo criterio que findStack) o la agrega al final
+func addToStock(stock []models.MerchantStockItem, entry models.MerchantStockItem) ([]models.MerchantStockItem, error) {
+	for i, s := range stock {
+		if hasCharges(entry.MagicData) || hasCharges(s.MagicData) {
+			continue
+		}
+		sameSlug := entry.Open5eSlug != "" && s.Open5eSlug == entry.Open5eSlug
+		sameName := entry.Open5eSlug == "" && s.Name == entry.Name && s.Type == entry.Type &&
+			s.WeaponData == nil && s.ArmorData == nil
+		if sameSlug || sameName {
+			stock[i].Unlimited = s.Unlimited || entry.Unlimited
+			if !stock[i].Unlimited {
+				stock[i].Quantity += entry.Quantity
+			}
+			return stock, nil
+		}
+	}
+
+	if len(stock) >= MAX_MERCHANT_STOCK {
+		return stock, errStockFull
+	}
+	return append(stock, entry), nil
+}
@@ -0,0 +1,311 @@
+// backend/internal/handlers/note_links.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+	"github.com/FranMaggi73/dm-events-backend/internal/search"
+)
+
+// ===========================
+// ENLACES ENTRE NOTAS (WIKI)
+// ===========================
+
+const (
+	MAX_LINKS_PER_NOTE = 100
+)
+
+var (
+	errUnresolvedLinks = errors.New("enlaces sin destino en la campaña")
+	errTooManyLinks    = fmt.Errorf("una nota admite hasta %d enlaces distintos", MAX_LINKS_PER_NOTE)
+)
+
+// wikiLinkPattern - [[destino]], [[tipo:destino]] o [[destino|texto]]
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]\n]{1,200})\]\]`)
+
+// linkKindAliases - prefijos aceptados en [[tipo:destino]] (inglés y español)
+var linkKindAliases = map[string]string{
+	"note": models.LinkKindNote, "nota": models.LinkKindNote,
+	"character": models.LinkKindCharacter, "personaje": models.LinkKindCharacter, "pj": models.LinkKindCharacter,
+	"npc": models.LinkKindNPC, "pnj": models.LinkKindNPC,
+	"location": models.LinkKindLocation, "lugar": models.LinkKindLocation,
+}
+
+// GetNoteBacklinks - Notas visibles para el usuario que enlazan a esta nota
+func (h *Handler) GetNoteBacklinks(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	noteID := c.Param("noteId")
+	ctx := context.Background()
+
+	noteDoc, err := h.db.Collection("notes").Doc(noteID).Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nota no encontrada"})
+		return
+	}
+	var note models.Note
+	if err := noteDoc.DataTo(&note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando nota"})
+		return
+	}
+	if !noteVisibleTo(note, uid) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver esta nota"})
+		return
+	}
+
+	// Los IDs son únicos: basta array-contains (sin índice compuesto con campaignId)
+	iter := h.db.Collection("notes").
+		Where("linkTargets", "array-contains", models.LinkKindNote+":"+noteID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	backlinks := []models.NoteBacklink{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo backlinks"})
+			return
+		}
+
+		var source models.Note
+		if err := doc.DataTo(&source); err != nil {
+			continue
+		}
+		if source.CampaignID != note.CampaignID || !noteVisibleTo(source, uid) {
+			continue
+		}
+
+		// Los enlaces dentro de secciones secretas solo cuentan para el autor
+		labels := []string{}
+		for _, link := range source.Links {
+			if link.TargetID == noteID && link.Kind != models.LinkKindCharacter && (!link.Secret || source.AuthorID == uid) {
+				labels = append(labels, link.Label)
+			}
+		}
+		if len(labels) == 0 {
+			continue
+		}
+		backlinks = append(backlinks, models.NoteBacklink{
+			NoteID:     source.ID,
+			Title:      source.Title,
+			Category:   source.Category,
+			AuthorName: source.AuthorName,
+			IsShared:   source.IsShared,
+			Labels:     uniqueStrings(labels),
+			UpdatedAt:  source.UpdatedAt,
+		})
+	}
+
+	sort.Slice(backlinks, func(i, j int) bool {
+		return backlinks[i].UpdatedAt.After(backlinks[j].UpdatedAt)
+	})
+
+	c.JSON(http.StatusOK, backlinks)
+}
+
+// ===========================
+// HELPERS DE ENLACES
+// ===========================
+
+// resolveNoteLinks resuelve los [[enlaces]] del contenido contra las notas que el autor
+// puede ver y los personajes de la campaña. Los enlaces que solo aparecen en secciones
+// secretas quedan marcados como Secret; fuera de ellas, una nota no privada solo puede
+// enlazar notas de toda la mesa, para no filtrar títulos de otras notas. Sin tipo se
+// busca primero una nota y después un personaje; se compara por ID o por nombre (sin
+// mayúsculas ni tildes). Los enlaces a la propia nota se ignoran. Devuelve los enlaces
+// y los destinos para el índice de backlinks.
+func (h *Handler) resolveNoteLinks(ctx context.Context, campaignID, uid, noteID, title, content string, shared bool) ([]models.NoteLink, []string, error) {
+	links := []models.NoteLink{}
+	targets := []string{}
+
+	matches := wikiLinkPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return links, targets, nil
+	}
+
+	raws := []string{}
+	seen := map[string]bool{}
+	for _, match := range matches {
+		raw := strings.TrimSpace(match[1])
+		if raw != "" && !seen[raw] {
+			seen[raw] = true
+			raws = append(raws, raw)
+		}
+	}
+	if len(raws) > MAX_LINKS_PER_NOTE {
+		return nil, nil, errTooManyLinks
+	}
+
+	public := map[string]bool{}
+	for _, match := range wikiLinkPattern.FindAllStringSubmatch(stripSecretSections(content), -1) {
+		public[strings.TrimSpace(match[1])] = true
+	}
+
+	notes, characters, err := h.linkCandidates(ctx, campaignID, uid, noteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicNotes := []models.Note{}
+	for _, note := range notes {
+		if noteAudience(note) == models.NoteAudiencePlayers {
+			publicNotes = append(publicNotes, note)
+		}
+	}
+
+	unresolved := []string{}
+	for _, raw := range raws {
+		target, label := raw, ""
+		if i := strings.Index(raw, "|"); i >= 0 {
+			target, label = strings.TrimSpace(raw[:i]), strings.TrimSpace(raw[i+1:])
+		}
+		kind := ""
+		if i := strings.Index(target, ":"); i > 0 {
+			if alias, ok := linkKindAliases[search.Normalize(strings.TrimSpace(target[:i]))]; ok {
+				kind, target = alias, strings.TrimSpace(target[i+1:])
+			}
+		}
+		if label == "" {
+			label = target
+		}
+
+		if noteID != "" && kind != models.LinkKindCharacter && (target == noteID || search.Normalize(target) == search.Normalize(title)) {
+			continue
+		}
+
+		candidates := notes
+		if shared && public[raw] {
+			candidates = publicNotes
+		}
+		link, ok := matchLink(kind, target, candidates, characters)
+		if !ok {
+			unresolved = append(unresolved, raw)
+			continue
+		}
+		link.Raw = raw
+		link.Label = label
+		link.Secret = !public[raw]
+		links = append(links, link)
+
+		targetKey := models.LinkKindNote + ":" + link.TargetID
+		if link.Kind == models.LinkKindCharacter {
+			targetKey = models.LinkKindCharacter + ":" + link.TargetID
+		}
+		targets = append(targets, targetKey)
+	}
+
+	if len(unresolved) > 0 {
+		return nil, nil, fmt.Errorf("%w: [[%s]]", errUnresolvedLinks, strings.Join(unresolved, "]], [["))
+	}
+	return links, uniqueStrings(targets), nil
+}
+
+// respondNoteLinkError traduce los errores de resolveNoteLinks a respuestas HTTP
+func respondNoteLinkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errUnresolvedLinks), errors.Is(err, errTooManyLinks):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error resolviendo enlaces"})
+	}
+}
+
+// matchLink busca el destino del enlace; entre varias notas con el mismo título gana
+// la actualizada más recientemente
+func matchLink(kind, target string, notes []models.Note, characters []models.Character) (models.NoteLink, bool) {
+	name := search.Normalize(target)
+
+	if kind != models.LinkKindCharacter {
+		var best *models.Note
+		for i, note := range notes {
+			if kind == models.LinkKindNPC && note.Category != "npc" || kind == models.LinkKindLocation && note.Category != "location" {
+				continue
+			}
+			if note.ID != target && search.Normalize(note.Title) != name {
+				continue
+			}
+			if best == nil || note.UpdatedAt.After(best.UpdatedAt) {
+				best = &notes[i]
+			}
+		}
+		if best != nil {
+			linkKind := models.LinkKindNote
+			switch best.Category {
+			case "npc":
+				linkKind = models.LinkKindNPC
+			case "location":
+				linkKind = models.LinkKindLocation
+			}
+			return models.NoteLink{Kind: linkKind, TargetID: best.ID, Title: best.Title}, true
+		}
+		if kind != "" {
+			return models.NoteLink{}, false
+		}
+	}
+
+	for _, character := range characters {
+		if character.ID == target || search.Normalize(character.Name) == name {
+			return models.NoteLink{Kind: models.LinkKindCharacter, TargetID: character.ID, Title: character.Name}, true
+		}
+	}
+	return models.NoteLink{}, false
+}
+
+// linkCandidates obtiene las notas visibles para el usuario (sin la nota editada) y los
+// personajes de la campaña
+func (h *Handler) linkCandidates(ctx context.Context, campaignID, uid, noteID string) ([]models.Note, []models.Character, error) {
+	notes := []models.Note{}
+	noteIter := h.db.Collection("notes").Where("campaignId", "==", campaignID).Documents(ctx)
+	defer noteIter.Stop()
+	for {
+		doc, err := noteIter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var note models.Note
+		if err := doc.DataTo(&note); err != nil || note.ID == noteID {
+			continue
+		}
+		if noteVisibleTo(note, uid) {
+			notes = append(notes, note)
+		}
+	}
+
+	characters := []models.Character{}
+	charIter := h.db.Collection("characters").Where("campaignId", "==", campaignID).Documents(ctx)
+	defer charIter.Stop()
+	for {
+		doc, err := charIter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var character models.Character
+		if err := doc.DataTo(&character); err != nil {
+			continue
+		}
+		characters = append(characters, character)
+	}
+
+	return notes, characters, nil
+}
@@ -0,0 +1,173 @@
+// backend/internal/handlers/note_search.go
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+	"github.com/FranMaggi73/dm-events-backend/internal/search"
+)
+
+// ===========================
+// BÚSQUEDA DE NOTAS
+// ===========================
+
+// El índice de cada campaña vive en el caché ("notes_index:<campaignId>"): se arma
+// en la primera búsqueda y se descarta (en todas las instancias) al crear, editar o
+// eliminar una nota, así la siguiente búsqueda lo reconstruye.
+const noteIndexTTL = 10 * time.Minute
+
+// noteIndex - índices invertidos de las notas de una campaña junto con las notas. El
+// índice público no contiene las secciones secretas: solo el DM (autor de todas las
+// notas que comparte) busca en el completo.
+type noteIndex struct {
+	index  *search.Index
+	public *search.Index
+	notes  map[string]models.Note
+}
+
+// SearchCampaignNotes - Buscar en las notas visibles (q, category, tags, limit, offset).
+// Sin q filtra y ordena por fecha de actualización.
+func (h *Handler) SearchCampaignNotes(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	query := search.Query{
+		Text:     strings.TrimSpace(c.Query("q")),
+		Category: c.Query("category"),
+	}
+	if len(query.Text) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "La búsqueda admite hasta 200 caracteres"})
+		return
+	}
+	for _, raw := range c.QueryArray("tags") {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				query.Tags = append(query.Tags, tag)
+			}
+		}
+	}
+	for param, target := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		if raw := c.Query(param); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " inválido"})
+				return
+			}
+			*target = value
+		}
+	}
+
+	campaign, err := h.getCampaignByID(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+	ix, err := h.campaignNoteIndex(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error buscando notas"})
+		return
+	}
+
+	// Mismas reglas que GetCampaignNotes: notas propias + las de su audiencia. El índice
+	// tiene todas las notas de la campaña, así que también resuelve los destinos de enlaces.
+	query.Visible = func(id string) bool {
+		note, ok := ix.notes[id]
+		return ok && noteVisibleTo(note, uid)
+	}
+
+	index := ix.public
+	if campaign.DmID == uid {
+		index = ix.index
+	}
+	result := index.Search(query)
+	response := models.NoteSearchResponse{
+		Hits:   make([]models.NoteSearchHit, 0, len(result.Hits)),
+		Total:  result.Total,
+		Limit:  minInt(maxInt(query.Limit, 0), search.MaxLimit),
+		Offset: query.Offset,
+	}
+	if response.Limit == 0 {
+		response.Limit = search.DefaultLimit
+	}
+	for _, hit := range result.Hits {
+		response.Hits = append(response.Hits, models.NoteSearchHit{
+			Note:    redactNote(ix.notes[hit.ID], uid, query.Visible),
+			Score:   hit.Score,
+			Title:   hit.Title,
+			Snippet: hit.Snippet,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ===========================
+// HELPERS DEL ÍNDICE
+// ===========================
+
+// campaignNoteIndex devuelve el índice de la campaña desde el caché o lo arma desde Firestore
+func (h *Handler) campaignNoteIndex(ctx context.Context, campaignID string) (*noteIndex, error) {
+	key := "notes_index:" + campaignID
+	if cached, _, ok := h.cache.Get(key); ok {
+		if ix, ok := cached.(*noteIndex); ok {
+			return ix, nil
+		}
+	}
+
+	iter := h.db.Collection("notes").
+		Where("campaignId", "==", campaignID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	ix := &noteIndex{notes: map[string]models.Note{}}
+	docs, publicDocs := []search.Document{}, []search.Document{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var note models.Note
+		if err := doc.DataTo(&note); err != nil {
+			continue
+		}
+		ix.notes[note.ID] = note
+		entry := search.Document{
+			ID:        note.ID,
+			Title:     note.Title,
+			Content:   note.Content,
+			Category:  note.Category,
+			Tags:      note.Tags,
+			UpdatedAt: note.UpdatedAt,
+		}
+		docs = append(docs, entry)
+		entry.Content = stripSecretSections(note.Content)
+		publicDocs = append(publicDocs, entry)
+	}
+	ix.index = search.NewIndex(docs)
+	ix.public = search.NewIndex(publicDocs)
+
+	h.cache.SetWithTTL(key, ix, noteIndexTTL)
+	return ix, nil
+}
+
+// invalidateNoteIndex descarta el índice de notas de la campaña
+func (h *Handler) invalidateNoteIndex(ctx context.Context, campaignID string) {
+	h.invalidatePattern(ctx, "notes_index:"+campaignID)
+}
@@ -0,0 +1,294 @@
+// backend/internal/handlers/note_sharing.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// AUDIENCIAS Y SECRETOS DE NOTAS
+// ===========================
+
+// Las secciones secretas se escriben en bloques que solo ve el autor de la nota (el DM,
+// único que puede compartir notas):
+//
+//	:::secret
+//	El posadero es el cultista.
+//	:::
+
+var (
+	errNoteRecipientsRequired = errors.New("una nota para jugadores específicos necesita al menos un destinatario")
+	errNoteRecipientNotPlayer = errors.New("el destinatario no es jugador de la campaña")
+)
+
+// RevealNote - El DM revela una nota a jugadores durante la sesión (pasa a "specific")
+func (h *Handler) RevealNote(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	noteID := c.Param("noteId")
+	ctx := context.Background()
+
+	var req models.RevealNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	noteRef := h.db.Collection("notes").Doc(noteID)
+	noteDoc, err := noteRef.Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nota no encontrada"})
+		return
+	}
+	var note models.Note
+	if err := noteDoc.DataTo(&note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando nota"})
+		return
+	}
+
+	campaign, err := h.getCampaignByID(ctx, note.CampaignID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+	if note.AuthorID != uid || campaign.DmID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Solo el DM puede revelar sus notas"})
+		return
+	}
+
+	// Ya visible para toda la mesa: nada que revelar
+	if noteAudience(note) == models.NoteAudiencePlayers {
+		c.JSON(http.StatusOK, note)
+		return
+	}
+
+	recipients, err := h.noteRecipients(ctx, note.CampaignID, uid, append(append([]string{}, note.SharedWith...), req.UserIDs...))
+	if err != nil {
+		respondNoteAudienceError(c, err)
+		return
+	}
+
+	// Al dejar de ser privada, fuera de las secciones secretas solo puede enlazar notas de toda la mesa
+	links, targets, err := h.resolveNoteLinks(ctx, note.CampaignID, uid, noteID, note.Title, note.Content, true)
+	if err != nil {
+		respondNoteLinkError(c, err)
+		return
+	}
+
+	_, err = noteRef.Update(ctx, []firestore.Update{
+		{Path: "audience", Value: models.NoteAudienceSpecific},
+		{Path: "sharedWith", Value: recipients},
+		{Path: "isShared", Value: false},
+		{Path: "links", Value: links},
+		{Path: "linkTargets", Value: targets},
+		{Path: "updatedAt", Value: time.Now()},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error revelando nota"})
+		return
+	}
+	h.invalidateNoteIndex(ctx, note.CampaignID)
+
+	note.Audience = models.NoteAudienceSpecific
+	note.SharedWith = recipients
+	note.IsShared = false
+	note.Links = links
+	note.UpdatedAt = time.Now()
+	c.JSON(http.StatusOK, note)
+}
+
+// ===========================
+// HELPERS DE AUDIENCIA
+// ===========================
+
+// noteAudience devuelve la audiencia de la nota; las anteriores a las audiencias solo
+// tienen IsShared
+func noteAudience(note models.Note) string {
+	if note.Audience != "" {
+		return note.Audience
+	}
+	if note.IsShared {
+		return models.NoteAudiencePlayers
+	}
+	return models.NoteAudiencePrivate
+}
+
+// noteVisibleTo indica si el usuario puede ver la nota
+func noteVisibleTo(note models.Note, uid string) bool {
+	if note.AuthorID == uid {
+		return true
+	}
+	switch noteAudience(note) {
+	case models.NoteAudiencePlayers:
+		return true
+	case models.NoteAudienceSpecific:
+		for _, id := range note.SharedWith {
+			if id == uid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactNote quita los enlaces a notas que el usuario ya no puede ver (visible), para
+// no filtrar sus títulos, y lo que solo ve el autor: secciones secretas, enlaces que
+// aparecen únicamente en ellas y la lista de destinatarios
+func redactNote(note models.Note, uid string, visible func(noteID string) bool) models.Note {
+	isAuthor := note.AuthorID == uid
+	links := []models.NoteLink{}
+	for _, link := range note.Links {
+		if link.Secret && !isAuthor {
+			continue
+		}
+		if link.Kind != models.LinkKindCharacter && !visible(link.TargetID) {
+			continue
+		}
+		links = append(links, link)
+	}
+	note.Links = links
+
+	if isAuthor {
+		return note
+	}
+	note.Content = stripSecretSections(note.Content)
+	note.SharedWith = []string{}
+	return note
+}
+
+// noteLinkVisibility indica qué notas enlazadas desde notes puede ver el usuario. Las
+// notas de la lista ya son visibles; del resto de los destinos se leen solo los documentos.
+func (h *Handler) noteLinkVisibility(ctx context.Context, uid string, notes []models.Note) (func(noteID string) bool, error) {
+	visible := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		visible[note.ID] = true
+	}
+
+	refs := []*firestore.DocumentRef{}
+	seen := map[string]bool{}
+	for _, note := range notes {
+		for _, link := range note.Links {
+			if link.Kind == models.LinkKindCharacter || link.TargetID == "" || visible[link.TargetID] || seen[link.TargetID] {
+				continue
+			}
+			seen[link.TargetID] = true
+			refs = append(refs, h.db.Collection("notes").Doc(link.TargetID))
+		}
+	}
+
+	if len(refs) > 0 {
+		docs, err := h.db.GetAll(ctx, refs)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			if !doc.Exists() {
+				continue
+			}
+			var target models.Note
+			if doc.DataTo(&target) == nil && noteVisibleTo(target, uid) {
+				visible[doc.Ref.ID] = true
+			}
+		}
+	}
+
+	return func(noteID string) bool {
+		return visible[noteID]
+	}, nil
+}
+
+// stripSecretSections elimina los bloques :::secret ... ::: del contenido. Un bloque
+// sin cerrar se considera secreto hasta el final.
+func stripSecretSections(content string) string {
+	if !strings.Contains(content, ":::") {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	public := make([]string, 0, len(lines))
+	inSecret := false
+	for _, line := range lines {
+		trimmed := strings.ToLower(strings.TrimSpace(line))
+		if inSecret {
+			if trimmed == ":::" {
+				inSecret = false
+			}
+			continue
+		}
+		if trimmed == ":::secret" || trimmed == ":::secreto" {
+			inSecret = true
+			continue
+		}
+		public = append(public, line)
+	}
+	return strings.Join(public, "\n")
+}
+
+// resolveNoteAudience valida la audiencia pedida (Audience o, si falta, IsShared) y
+// devuelve la audiencia, los destinatarios y el valor de isShared
+func (h *Handler) resolveNoteAudience(ctx context.Context, campaignID, authorID, audience string, isShared bool, sharedWith []string) (string, []string, bool, error) {
+	if audience == "" {
+		audience = models.NoteAudiencePrivate
+		if isShared {
+			audience = models.NoteAudiencePlayers
+		}
+	}
+	if audience != models.NoteAudienceSpecific {
+		return audience, []string{}, audience == models.NoteAudiencePlayers, nil
+	}
+
+	recipients, err := h.noteRecipients(ctx, campaignID, authorID, sharedWith)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return audience, recipients, false, nil
+}
+
+// noteRecipients valida que los destinatarios sean jugadores de la campaña (sin el autor)
+func (h *Handler) noteRecipients(ctx context.Context, campaignID, authorID string, userIDs []string) ([]string, error) {
+	members, err := h.getCampaignMembers(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, member := range members {
+		isMember[member.UserID] = true
+	}
+
+	recipients := []string{}
+	for _, id := range uniqueStrings(userIDs) {
+		if id == authorID {
+			continue
+		}
+		if !isMember[id] {
+			return nil, fmt.Errorf("%w: %s", errNoteRecipientNotPlayer, id)
+		}
+		recipients = append(recipients, id)
+	}
+	if len(recipients) == 0 {
+		return nil, errNoteRecipientsRequired
+	}
+	return recipients, nil
+}
+
+// respondNoteAudienceError traduce los errores de audiencia a respuestas HTTP
+func respondNoteAudienceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errNoteRecipientsRequired), errors.Is(err, errNoteRecipientNotPlayer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error validando destinatarios"})
+	}
+}
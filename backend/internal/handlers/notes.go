@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/firestore"
@@ -48,8 +49,14 @@ func (h *Handler) CreateNote(c *gin.Context) {
 	var member models.CampaignMember
 	memberDoc.DataTo(&member)
 
-	// Si isShared=true, verificar que es DM
-	if req.IsShared {
+	audience, sharedWith, isShared, err := h.resolveNoteAudience(ctx, campaignId, uid, req.Audience, req.IsShared, req.SharedWith)
+	if err != nil {
+		respondNoteAudienceError(c, err)
+		return
+	}
+
+	// Si la nota no es privada, verificar que es DM
+	if audience != models.NoteAudiencePrivate {
 		campaignDoc, err := h.db.Collection("events").Doc(campaignId).Get(ctx)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
@@ -84,7 +91,9 @@ func (h *Handler) CreateNote(c *gin.Context) {
 		AuthorName: user.DisplayName,
 		Title:      req.Title,
 		Content:    req.Content,
-		IsShared:   req.IsShared,
+		IsShared:   isShared,
+		Audience:   audience,
+		SharedWith: sharedWith,
 		Category:   req.Category,
 		Tags:       req.Tags,
 		CreatedAt:  time.Now(),
@@ -95,16 +104,24 @@ func (h *Handler) CreateNote(c *gin.Context) {
 		note.Tags = []string{}
 	}
 
+	note.Links, note.LinkTargets, err = h.resolveNoteLinks(ctx, campaignId, uid, note.ID, note.Title, note.Content, audience != models.NoteAudiencePrivate)
+	if err != nil {
+		respondNoteLinkError(c, err)
+		return
+	}
+
 	if _, err := noteRef.Set(ctx, note); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando nota"})
 		return
 	}
+	h.invalidateNoteIndex(ctx, campaignId)
 
 	c.JSON(http.StatusCreated, note)
 }
 
 // GetCampaignNotes - Obtener todas las notas de una campaña
-// Devuelve: notas personales del usuario + notas compartidas del DM
+// Devuelve: notas personales del usuario + notas compartidas del DM (con toda la mesa
+// o con el usuario), sin las secciones secretas
 func (h *Handler) GetCampaignNotes(c *gin.Context) {
 	uid := c.GetString("uid")
 	if uid == "" {
@@ -174,10 +191,43 @@ func (h *Handler) GetCampaignNotes(c *gin.Context) {
 		notes = append(notes, note)
 	}
 
+	// 3. Obtener notas compartidas con el usuario. Los IDs de usuario bastan para
+	// array-contains; la campaña se filtra acá (sin índice compuesto).
+	specificIter := h.db.Collection("notes").
+		Where("sharedWith", "array-contains", uid).
+		Documents(ctx)
+
+	for {
+		doc, err := specificIter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		var note models.Note
+		if err := doc.DataTo(&note); err != nil {
+			continue
+		}
+		if note.CampaignID == campaignId && noteVisibleTo(note, uid) {
+			notes = append(notes, note)
+		}
+	}
+
 	if notes == nil {
 		notes = []models.Note{}
 	}
 
+	visible, err := h.noteLinkVisibility(ctx, uid, notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo notas"})
+		return
+	}
+	for i := range notes {
+		notes[i] = redactNote(notes[i], uid, visible)
+	}
+
 	c.JSON(http.StatusOK, notes)
 }
 
@@ -203,13 +253,19 @@ func (h *Handler) GetNote(c *gin.Context) {
 		return
 	}
 
-	// Verificar permisos: autor o nota compartida
-	if note.AuthorID != uid && !note.IsShared {
+	// Verificar permisos: autor o parte de la audiencia
+	if !noteVisibleTo(note, uid) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver esta nota"})
 		return
 	}
 
-	c.JSON(http.StatusOK, note)
+	visible, err := h.noteLinkVisibility(ctx, uid, []models.Note{note})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo nota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, redactNote(note, uid, visible))
 }
 
 // UpdateNote - Actualizar una nota
@@ -246,8 +302,23 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 		return
 	}
 
-	// Si intenta cambiar isShared, verificar que es DM
-	if req.IsShared != note.IsShared {
+	// Sin audience y sin cambiar isShared se conserva la audiencia actual (notas reveladas)
+	if req.Audience == "" && req.IsShared == note.IsShared {
+		req.Audience = noteAudience(note)
+		if req.SharedWith == nil {
+			req.SharedWith = note.SharedWith
+		}
+	}
+
+	audience, sharedWith, isShared, err := h.resolveNoteAudience(ctx, note.CampaignID, uid, req.Audience, req.IsShared, req.SharedWith)
+	if err != nil {
+		respondNoteAudienceError(c, err)
+		return
+	}
+	audienceChanged := audience != noteAudience(note) || strings.Join(sharedWith, ",") != strings.Join(note.SharedWith, ",")
+
+	// Si intenta cambiar la audiencia, verificar que es DM
+	if audienceChanged {
 		campaignDoc, err := h.db.Collection("events").Doc(note.CampaignID).Get(ctx)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
@@ -270,10 +341,31 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 	if req.Title != "" {
 		updates = append(updates, firestore.Update{Path: "title", Value: req.Title})
 	}
+	// Los enlaces se resuelven de nuevo si cambia el contenido o la visibilidad
+	title, content := note.Title, note.Content
+	if req.Title != "" {
+		title = req.Title
+	}
 	if req.Content != "" {
+		content = req.Content
 		updates = append(updates, firestore.Update{Path: "content", Value: req.Content})
 	}
-	updates = append(updates, firestore.Update{Path: "isShared", Value: req.IsShared})
+	if req.Content != "" || audienceChanged {
+		links, targets, err := h.resolveNoteLinks(ctx, note.CampaignID, uid, noteId, title, content, audience != models.NoteAudiencePrivate)
+		if err != nil {
+			respondNoteLinkError(c, err)
+			return
+		}
+		updates = append(updates,
+			firestore.Update{Path: "links", Value: links},
+			firestore.Update{Path: "linkTargets", Value: targets},
+		)
+	}
+	updates = append(updates,
+		firestore.Update{Path: "isShared", Value: isShared},
+		firestore.Update{Path: "audience", Value: audience},
+		firestore.Update{Path: "sharedWith", Value: sharedWith},
+	)
 	if req.Category != "" {
 		updates = append(updates, firestore.Update{Path: "category", Value: req.Category})
 	}
@@ -285,6 +377,7 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando nota"})
 		return
 	}
+	h.invalidateNoteIndex(ctx, note.CampaignID)
 
 	// Obtener nota actualizada
 	updatedDoc, _ := h.db.Collection("notes").Doc(noteId).Get(ctx)
@@ -326,6 +419,7 @@ func (h *Handler) DeleteNote(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando nota"})
 		return
 	}
+	h.invalidateNoteIndex(ctx, note.CampaignID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Nota eliminada"})
 }
@@ -243,6 +243,13 @@ func (h *Handler) getCampaignCharacters(ctx context.Context, campaignID string)
 		if err := doc.DataTo(&char); err != nil {
 			continue
 		}
+		// Personajes anteriores a los recursos: exponer listas vacías
+		if char.Resources == nil {
+			char.Resources = []models.Resource{}
+		}
+		if char.HitDice == nil {
+			char.HitDice = []models.HitDicePool{}
+		}
 		characters = append(characters, char)
 	}
 
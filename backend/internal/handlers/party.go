@@ -0,0 +1,505 @@
+// backend/internal/handlers/party.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// FONDO DEL GRUPO
+// ===========================
+
+const (
+	MAX_PARTY_ITEMS = 500
+
+	// Cada destinatario suma 3 escrituras (monedas, libro y auditoría) a una transacción
+	// limitada a 500
+	MAX_SPLIT_RECIPIENTS = 50
+)
+
+var (
+	errPartyItemNotFound  = errors.New("item no encontrado en el fondo del grupo")
+	errPartyFull          = fmt.Errorf("el fondo del grupo alcanzó el límite de %d items", MAX_PARTY_ITEMS)
+	errClaimNoCharacter   = errors.New("no tienes un personaje en esta campaña")
+	errClaimForbidden     = errors.New("solo puedes reclamar items para tu personaje")
+	errSplitNoRecipients  = errors.New("no hay personajes entre los que repartir")
+	errSplitInvalidTarget = errors.New("todos los personajes deben pertenecer a la campaña")
+	errSplitEmpty         = errors.New("el fondo del grupo no tiene monedas")
+	errSplitTooMany       = fmt.Errorf("se puede repartir entre hasta %d personajes", MAX_SPLIT_RECIPIENTS)
+)
+
+// GetPartyStash - Items y monedas del fondo del grupo
+func (h *Handler) GetPartyStash(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	iter := h.db.Collection("party_items").
+		Where("campaignId", "==", campaignID).
+		Documents(ctx)
+
+	items := []models.InventoryItem{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo fondo del grupo"})
+			return
+		}
+
+		var item models.InventoryItem
+		if err := doc.DataTo(&item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	currency := models.Currency{}
+	if doc, err := h.db.Collection("party_currencies").Doc(campaignID).Get(ctx); err == nil {
+		doc.DataTo(&currency)
+	}
+	itemsWorth, coinsWorth, netWorth := inventoryWorth(items, currency)
+
+	c.JSON(http.StatusOK, models.PartyStashResponse{
+		Items:      items,
+		Currency:   currency,
+		TotalValue: float64(netWorth.TotalCP) / 100,
+		ItemsWorth: itemsWorth,
+		CoinsWorth: coinsWorth,
+		NetWorth:   netWorth,
+	})
+}
+
+// AddPartyItem - El DM deja botín en el fondo del grupo (apila como CreateItem)
+func (h *Handler) AddPartyItem(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.applyCompendium(ctx, campaignID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	loot := newItemFromRequest(req)
+	loot.CampaignID = campaignID
+	loot.ContainerID = ""
+	loot.CreatedAt = now
+	loot.UpdatedAt = now
+	if err := validateItem(&loot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var saved models.InventoryItem
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		items, err := characterItemsInTx(tx, h.db.Collection("party_items").Where("campaignId", "==", campaignID))
+		if err != nil {
+			return err
+		}
+
+		if stack := findStack(items, loot, loot.Quantity); stack != -1 {
+			existing := items[stack]
+			existing.Quantity += loot.Quantity
+			existing.UpdatedAt = now
+			saved = existing
+			return tx.Update(h.db.Collection("party_items").Doc(existing.ID), []firestore.Update{
+				{Path: "quantity", Value: existing.Quantity},
+				{Path: "updatedAt", Value: now},
+			})
+		}
+
+		if len(items) >= MAX_PARTY_ITEMS {
+			return errPartyFull
+		}
+
+		ref := h.db.Collection("party_items").NewDoc()
+		saved = loot
+		saved.ID = ref.ID
+		return tx.Set(ref, saved)
+	})
+
+	if err != nil {
+		if errors.Is(err, errPartyFull) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error agregando botín"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, saved)
+}
+
+// DeletePartyItem - Quitar un item del fondo del grupo
+func (h *Handler) DeletePartyItem(c *gin.Context) {
+	campaignID := c.Param("id")
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	ref := h.db.Collection("party_items").Doc(itemID)
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errPartyItemNotFound.Error()})
+		return
+	}
+
+	var item models.InventoryItem
+	if err := doc.DataTo(&item); err != nil || item.CampaignID != campaignID {
+		c.JSON(http.StatusNotFound, gin.H{"error": errPartyItemNotFound.Error()})
+		return
+	}
+
+	if _, err := ref.Delete(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item eliminado"})
+}
+
+// AddPartyCurrency - El DM suma monedas al tesoro del grupo
+func (h *Handler) AddPartyCurrency(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.PartyCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ref := h.db.Collection("party_currencies").Doc(campaignID)
+	var currency models.Currency
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var err error
+		if currency, err = currencyInTx(tx, ref); err != nil {
+			return err
+		}
+		currency = addCurrency(currency, models.Currency{
+			Copper:   req.Copper,
+			Silver:   req.Silver,
+			Electrum: req.Electrum,
+			Gold:     req.Gold,
+			Platinum: req.Platinum,
+		}, 1)
+		if err := validateCurrency(currency); err != nil {
+			return err
+		}
+		return tx.Set(ref, currency)
+	})
+
+	if err != nil {
+		if errors.Is(err, errCurrencyOverflow) || errors.Is(err, errNegativeCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando tesoro"})
+		return
+	}
+
+	c.JSON(http.StatusOK, currency)
+}
+
+// SplitPartyCurrency - Repartir el tesoro a partes iguales. Lo que no se puede dividir
+// se cambia a la moneda inferior (1 ppt = 10 po = 100 pp = 1000 pc, 1 pe = 5 pp); el cobre sobrante queda en el fondo.
+func (h *Handler) SplitPartyCurrency(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.SplitCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipients := req.CharacterIDs
+	if len(recipients) == 0 {
+		characters, err := h.getCampaignCharacters(ctx, campaignID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo personajes"})
+			return
+		}
+		for _, char := range characters {
+			recipients = append(recipients, char.ID)
+		}
+	}
+	recipients = uniqueStrings(recipients)
+	if len(recipients) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errSplitNoRecipients.Error()})
+		return
+	}
+	if len(recipients) > MAX_SPLIT_RECIPIENTS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errSplitTooMany.Error()})
+		return
+	}
+
+	partyRef := h.db.Collection("party_currencies").Doc(campaignID)
+	var result models.SplitCurrencyResponse
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		treasury, err := currencyInTx(tx, partyRef)
+		if err != nil {
+			return err
+		}
+		if treasury == (models.Currency{}) {
+			return errSplitEmpty
+		}
+
+		balances := make([]models.Currency, len(recipients))
+		characters := make([]models.Character, len(recipients))
+		for i, charID := range recipients {
+			charDoc, err := tx.Get(h.db.Collection("characters").Doc(charID))
+			if err != nil {
+				return errSplitInvalidTarget
+			}
+			if err := charDoc.DataTo(&characters[i]); err != nil || characters[i].CampaignID != campaignID {
+				return errSplitInvalidTarget
+			}
+			if balances[i], err = currencyInTx(tx, h.db.Collection("currencies").Doc(charID)); err != nil {
+				return err
+			}
+		}
+
+		share, remainder := splitCurrency(treasury, len(recipients))
+		now := time.Now()
+
+		for i, charID := range recipients {
+			updated := addCurrency(balances[i], share, 1)
+			if err := validateCurrency(updated); err != nil {
+				return err
+			}
+			if err := tx.Set(h.db.Collection("currencies").Doc(charID), updated); err != nil {
+				return err
+			}
+			if _, err := h.ledgerEntryInTx(tx, &characters[i], uid, "Reparto del tesoro del grupo", "", balances[i], updated); err != nil {
+				return err
+			}
+
+			auditRef := h.db.Collection("inventory_transfers").NewDoc()
+			amount := share
+			if err := tx.Set(auditRef, models.InventoryTransfer{
+				ID:            auditRef.ID,
+				CampaignID:    campaignID,
+				Kind:          models.TransferKindCurrency,
+				ToCharacterID: charID,
+				PerformedBy:   uid,
+				Currency:      &amount,
+				CreatedAt:     now,
+			}); err != nil {
+				return err
+			}
+		}
+
+		result = models.SplitCurrencyResponse{
+			Share:      share,
+			Recipients: recipients,
+			Remainder:  remainder,
+		}
+		return tx.Set(partyRef, remainder)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errSplitEmpty), errors.Is(err, errSplitInvalidTarget), errors.Is(err, errCurrencyOverflow):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error repartiendo el tesoro"})
+		}
+		return
+	}
+
+	for _, charID := range recipients {
+		h.invalidateCharacterCache(ctx, charID)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ClaimPartyItem - Reclamar un item del fondo del grupo para un personaje
+func (h *Handler) ClaimPartyItem(c *gin.Context) {
+	uid := c.GetString("uid")
+	campaignID := c.Param("id")
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	var req models.ClaimPartyItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, err := h.getCampaignByID(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+	isDM := campaign.DmID == uid
+
+	itemRef := h.db.Collection("party_items").Doc(itemID)
+	var received models.InventoryItem
+	var targetID string
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errPartyItemNotFound
+		}
+		var loot models.InventoryItem
+		if err := itemDoc.DataTo(&loot); err != nil || loot.CampaignID != campaignID {
+			return errPartyItemNotFound
+		}
+		if loot.Quantity < req.Quantity {
+			return errTransferNotEnough
+		}
+
+		// Resolver el personaje destino
+		var target models.Character
+		if req.CharacterID != "" {
+			charDoc, err := tx.Get(h.db.Collection("characters").Doc(req.CharacterID))
+			if err != nil {
+				return errTransferTargetMissing
+			}
+			if err := charDoc.DataTo(&target); err != nil {
+				return err
+			}
+			if target.CampaignID != campaignID {
+				return errTransferOtherCampaign
+			}
+			if !isDM && target.UserID != uid {
+				return errClaimForbidden
+			}
+		} else {
+			charDoc, err := tx.Documents(h.db.Collection("characters").
+				Where("campaignId", "==", campaignID).
+				Where("userId", "==", uid).
+				Limit(1)).Next()
+			if err != nil {
+				return errClaimNoCharacter
+			}
+			if err := charDoc.DataTo(&target); err != nil {
+				return err
+			}
+		}
+		targetID = target.ID
+
+		targetItems, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", target.ID))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stack := findStack(targetItems, loot, req.Quantity)
+		if stack == -1 && len(targetItems) >= MAX_ITEMS_PER_CHARACTER {
+			return errTransferTargetFull
+		}
+
+		if loot.Quantity == req.Quantity {
+			if err := tx.Delete(itemRef); err != nil {
+				return err
+			}
+		} else if err := tx.Update(itemRef, []firestore.Update{
+			{Path: "quantity", Value: loot.Quantity - req.Quantity},
+			{Path: "updatedAt", Value: now},
+		}); err != nil {
+			return err
+		}
+
+		if stack != -1 {
+			received = targetItems[stack]
+			received.Quantity += req.Quantity
+			received.UpdatedAt = now
+			if err := tx.Update(h.db.Collection("inventory_items").Doc(received.ID), []firestore.Update{
+				{Path: "quantity", Value: received.Quantity},
+				{Path: "updatedAt", Value: now},
+			}); err != nil {
+				return err
+			}
+		} else {
+			newRef := h.db.Collection("inventory_items").NewDoc()
+			received = loot
+			received.ID = newRef.ID
+			received.CharacterID = target.ID
+			received.Quantity = req.Quantity
+			received.CreatedAt = now
+			received.UpdatedAt = now
+			if err := tx.Set(newRef, received); err != nil {
+				return err
+			}
+		}
+
+		auditRef := h.db.Collection("inventory_transfers").NewDoc()
+		return tx.Set(auditRef, models.InventoryTransfer{
+			ID:            auditRef.ID,
+			CampaignID:    campaignID,
+			Kind:          models.TransferKindItem,
+			ToCharacterID: target.ID,
+			PerformedBy:   uid,
+			ItemName:      loot.Name,
+			Quantity:      req.Quantity,
+			CreatedAt:     now,
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errPartyItemNotFound), errors.Is(err, errTransferTargetMissing), errors.Is(err, errClaimNoCharacter):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errClaimForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, errTransferNotEnough), errors.Is(err, errTransferOtherCampaign), errors.Is(err, errTransferTargetFull):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reclamando item"})
+		}
+		return
+	}
+
+	h.invalidateCharacterCache(ctx, targetID)
+
+	c.JSON(http.StatusOK, received)
+}
+
+// ===========================
+// HELPERS DEL FONDO
+// ===========================
+
+// splitCurrency divide el tesoro entre n. El resto de cada moneda se cambia a la inferior;
+// el cobre que no se puede dividir queda como remanente.
+func splitCurrency(total models.Currency, n int) (models.Currency, models.Currency) {
+	var share models.Currency
+
+	share.Platinum = total.Platinum / n
+	gold := total.Gold + (total.Platinum%n)*10
+
+	share.Gold = gold / n
+	share.Electrum = total.Electrum / n
+	silver := total.Silver + (gold%n)*10 + (total.Electrum%n)*5
+
+	share.Silver = silver / n
+	copper := total.Copper + (silver%n)*10
+
+	share.Copper = copper / n
+
+	return share, models.Currency{Copper: copper % n}
+}
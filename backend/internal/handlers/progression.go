@@ -0,0 +1,381 @@
+// backend/internal/handlers/progression.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// PROGRESIÓN (XP / HITOS)
+// ===========================
+
+var errCharacterNotInCampaign = errors.New("personaje no pertenece a la campaña")
+
+// xpThresholds - XP mínima para cada nivel (índice 0 = nivel 1), tabla del PHB
+var xpThresholds = [20]int{
+	0, 300, 900, 2700, 6500, 14000, 23000, 34000, 48000, 64000,
+	85000, 100000, 120000, 140000, 165000, 195000, 225000, 265000, 305000, 355000,
+}
+
+// xpForLevel devuelve la XP mínima necesaria para alcanzar un nivel
+func xpForLevel(level int) int {
+	if level < 1 {
+		return 0
+	}
+	if level > 20 {
+		level = 20
+	}
+	return xpThresholds[level-1]
+}
+
+// isReadyToLevelUp indica si la XP alcanza el umbral del siguiente nivel
+func isReadyToLevelUp(level, experience int) bool {
+	if level >= 20 {
+		return false
+	}
+	return experience >= xpForLevel(level+1)
+}
+
+// progressionMode devuelve el modo de progresión de la campaña (XP por defecto)
+func progressionMode(campaign *models.Campaign) string {
+	if campaign != nil && campaign.ProgressionMode == models.ProgressionMilestone {
+		return models.ProgressionMilestone
+	}
+	return models.ProgressionXP
+}
+
+// UpdateProgressionMode - Cambiar el modo de progresión de la campaña (solo DM)
+func (h *Handler) UpdateProgressionMode(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.UpdateProgressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.db.Collection("events").Doc(campaignID).Update(ctx, []firestore.Update{
+		{Path: "progressionMode", Value: req.Mode},
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando modo de progresión"})
+		return
+	}
+
+	// Al volver a XP, los flags de subida de nivel se recalculan con la tabla
+	recalculated := 0
+	if req.Mode == models.ProgressionXP {
+		characters, err := h.getCampaignCharacters(ctx, campaignID)
+		if err == nil && len(characters) > 0 {
+			batch := h.db.Batch()
+			for _, char := range characters {
+				batch.Update(h.db.Collection("characters").Doc(char.ID), []firestore.Update{
+					{Path: "readyToLevelUp", Value: isReadyToLevelUp(char.Level, char.Experience)},
+				})
+				recalculated++
+			}
+			if _, err := batch.Commit(ctx); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error recalculando niveles"})
+				return
+			}
+		}
+	}
+
+	h.invalidateCampaignCache(ctx, campaignID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Modo de progresión actualizado",
+		"mode":         req.Mode,
+		"recalculated": recalculated,
+	})
+}
+
+// AwardXP - Otorgar XP a uno o varios personajes de la campaña (solo DM)
+func (h *Handler) AwardXP(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.AwardXPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, err := h.getCampaignByID(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+
+	if progressionMode(campaign) != models.ProgressionXP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "La campaña usa progresión por hitos"})
+		return
+	}
+
+	characterIDs := uniqueStrings(req.CharacterIDs)
+
+	amount := req.Amount
+	if req.Split {
+		amount = req.Amount / len(characterIDs)
+		if amount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "La XP a repartir es menor que la cantidad de personajes"})
+			return
+		}
+	}
+
+	var awards []models.XPAward
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		awards = nil
+
+		characters, err := h.getCharactersForAward(tx, campaignID, characterIDs)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, char := range characters {
+			newXP := char.Experience + amount
+			ready := isReadyToLevelUp(char.Level, newXP)
+
+			updates := []firestore.Update{
+				{Path: "experience", Value: newXP},
+				{Path: "readyToLevelUp", Value: ready},
+				{Path: "updatedAt", Value: now},
+			}
+			if err := tx.Update(h.db.Collection("characters").Doc(char.ID), updates); err != nil {
+				return err
+			}
+			after := characterAfterUpdates(&char, updates)
+			if err := h.recordCharacterVersionInTx(tx, &char, &after, uid, models.CharacterVersionXP); err != nil {
+				return err
+			}
+
+			historyRef := h.db.Collection("xp_history").NewDoc()
+			award := models.XPAward{
+				ID:           historyRef.ID,
+				CharacterID:  char.ID,
+				CampaignID:   campaignID,
+				Type:         models.ProgressionXP,
+				Amount:       amount,
+				Reason:       req.Reason,
+				AwardedBy:    uid,
+				TotalAfter:   newXP,
+				LevelAtAward: char.Level,
+				CreatedAt:    now,
+			}
+			if err := tx.Set(historyRef, award); err != nil {
+				return err
+			}
+			awards = append(awards, award)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, errCharacterNotInCampaign) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error otorgando XP"})
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+campaignID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "XP otorgada",
+		"amountPerCharacter": amount,
+		"awards":             awards,
+	})
+}
+
+// AwardMilestone - Marcar uno o varios personajes como listos para subir de nivel (solo DM)
+func (h *Handler) AwardMilestone(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	var req models.AwardMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, err := h.getCampaignByID(ctx, campaignID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+
+	if progressionMode(campaign) != models.ProgressionMilestone {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "La campaña usa progresión por XP"})
+		return
+	}
+
+	characterIDs := uniqueStrings(req.CharacterIDs)
+	var awards []models.XPAward
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		awards = nil
+
+		characters, err := h.getCharactersForAward(tx, campaignID, characterIDs)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, char := range characters {
+			updates := []firestore.Update{
+				{Path: "readyToLevelUp", Value: char.Level < 20},
+				{Path: "updatedAt", Value: now},
+			}
+			if err := tx.Update(h.db.Collection("characters").Doc(char.ID), updates); err != nil {
+				return err
+			}
+			after := characterAfterUpdates(&char, updates)
+			if err := h.recordCharacterVersionInTx(tx, &char, &after, uid, models.CharacterVersionMilestone); err != nil {
+				return err
+			}
+
+			historyRef := h.db.Collection("xp_history").NewDoc()
+			award := models.XPAward{
+				ID:           historyRef.ID,
+				CharacterID:  char.ID,
+				CampaignID:   campaignID,
+				Type:         models.ProgressionMilestone,
+				Reason:       req.Reason,
+				AwardedBy:    uid,
+				TotalAfter:   char.Experience,
+				LevelAtAward: char.Level,
+				CreatedAt:    now,
+			}
+			if err := tx.Set(historyRef, award); err != nil {
+				return err
+			}
+			awards = append(awards, award)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, errCharacterNotInCampaign) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error otorgando hito"})
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+campaignID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hito otorgado",
+		"awards":  awards,
+	})
+}
+
+// GetCharacterXPHistory - Historial de XP e hitos de un personaje (más reciente primero)
+func (h *Handler) GetCharacterXPHistory(c *gin.Context) {
+	characterID := c.Param("charId")
+	ctx := context.Background()
+
+	iter := h.db.Collection("xp_history").
+		Where("characterId", "==", characterID).
+		OrderBy("createdAt", firestore.Desc).
+		Limit(200).
+		Documents(ctx)
+
+	var history []models.XPAward
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo historial de XP"})
+			return
+		}
+
+		var award models.XPAward
+		if err := doc.DataTo(&award); err != nil {
+			continue
+		}
+		history = append(history, award)
+	}
+
+	if history == nil {
+		history = []models.XPAward{}
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// ===========================
+// HELPERS PRIVADOS
+// ===========================
+
+// getCharactersForAward lee los personajes dentro de la transacción y verifica que sean de la campaña
+func (h *Handler) getCharactersForAward(tx *firestore.Transaction, campaignID string, characterIDs []string) ([]models.Character, error) {
+	refs := make([]*firestore.DocumentRef, len(characterIDs))
+	for i, id := range characterIDs {
+		refs[i] = h.db.Collection("characters").Doc(id)
+	}
+
+	docs, err := tx.GetAll(refs)
+	if err != nil {
+		return nil, err
+	}
+
+	characters := make([]models.Character, 0, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			return nil, errCharacterNotInCampaign
+		}
+
+		var char models.Character
+		if err := doc.DataTo(&char); err != nil {
+			return nil, err
+		}
+		if char.CampaignID != campaignID {
+			return nil, errCharacterNotInCampaign
+		}
+		characters = append(characters, char)
+	}
+
+	return characters, nil
+}
+
+// uniqueStrings elimina duplicados conservando el orden
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,274 @@
+// backend/internal/handlers/resources.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// RECURSOS DE CLASE Y DADOS DE GOLPE
+// ===========================
+
+var (
+	errResourceNotFound    = errors.New("recurso no encontrado")
+	errFeatureNotFound     = errors.New("rasgo no encontrado")
+	errFeatureNotLimited   = errors.New("el rasgo no tiene usos limitados")
+	errNotEnoughResource   = errors.New("no quedan usos suficientes")
+	errHitDiceNotFound     = errors.New("el personaje no tiene dados de golpe de ese tamaño")
+	errNotEnoughHitDice    = errors.New("no quedan dados de golpe suficientes")
+	errHitDiceAtFullHealth = errors.New("el personaje ya tiene los puntos de golpe al máximo")
+)
+
+// SpendResource - Gastar usos de un recurso de clase (Furia, Ki...)
+func (h *Handler) SpendResource(c *gin.Context) {
+	h.changeResource(c, true)
+}
+
+// RestoreResource - Recuperar usos de un recurso de clase (0 = al máximo)
+func (h *Handler) RestoreResource(c *gin.Context) {
+	h.changeResource(c, false)
+}
+
+func (h *Handler) changeResource(c *gin.Context, spend bool) {
+	charID := c.Param("charId")
+	resourceID := c.Param("resourceId")
+	ctx := context.Background()
+
+	var req models.ResourceAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+	var changed models.Resource
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+
+		index := -1
+		for i, r := range character.Resources {
+			if r.ID == resourceID {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return errResourceNotFound
+		}
+
+		resource := character.Resources[index]
+		if spend {
+			amount := req.Amount
+			if amount == 0 {
+				amount = 1
+			}
+			if resource.Current < amount {
+				return errNotEnoughResource
+			}
+			resource.Current -= amount
+		} else {
+			if req.Amount == 0 {
+				resource.Current = resource.Max
+			} else {
+				resource.Current = minInt(resource.Current+req.Amount, resource.Max)
+			}
+		}
+
+		character.Resources[index] = resource
+		changed = resource
+
+		return tx.Update(charRef, []firestore.Update{
+			{Path: "resources", Value: character.Resources},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errResourceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errNotEnoughResource):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando recurso"})
+		}
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+
+	c.JSON(http.StatusOK, changed)
+}
+
+// SpendHitDice - Gastar dados de golpe (descanso corto): tira cada dado + mod. de CON y cura
+func (h *Handler) SpendHitDice(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.SpendHitDiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+	var rolls []int
+	healed := 0
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		rolls = nil
+		healed = 0
+
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+
+		index := -1
+		for i, pool := range character.HitDice {
+			if pool.Die == req.Die {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return errHitDiceNotFound
+		}
+		if character.HitDice[index].Current < req.Count {
+			return errNotEnoughHitDice
+		}
+		if character.CurrentHP >= character.MaxHP {
+			return errHitDiceAtFullHealth
+		}
+
+		conMod := abilityModifier(character.AbilityScores.Constitution)
+		total := 0
+		for i := 0; i < req.Count; i++ {
+			roll := rollDie(req.Die)
+			rolls = append(rolls, roll)
+			if gain := roll + conMod; gain > 0 {
+				total += gain
+			}
+		}
+
+		newHP := minInt(character.CurrentHP+total, character.MaxHP)
+		healed = newHP - character.CurrentHP
+		character.CurrentHP = newHP
+		character.HitDice[index].Current -= req.Count
+
+		return tx.Update(charRef, []firestore.Update{
+			{Path: "currentHp", Value: character.CurrentHP},
+			{Path: "hitDice", Value: character.HitDice},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errHitDiceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errNotEnoughHitDice), errors.Is(err, errHitDiceAtFullHealth):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error gastando dados de golpe"})
+		}
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"rolls":     rolls,
+		"healed":    healed,
+		"currentHp": character.CurrentHP,
+		"hitDice":   character.HitDice,
+	})
+}
+
+// ===========================
+// HELPERS DE RECURSOS
+// ===========================
+
+// rechargeResources recarga los recursos según el tipo de descanso.
+// Corto: "short". Largo: "short", "long" y "dawn" (el amanecer ocurre durante el descanso largo).
+func rechargeResources(resources []models.Resource, restType string) ([]models.Resource, []string) {
+	result := make([]models.Resource, len(resources))
+	copy(result, resources)
+	recharged := []string{}
+
+	for i, r := range result {
+		if r.Current >= r.Max {
+			continue
+		}
+		switch {
+		case r.Recharge == models.RechargeShortRest,
+			restType == models.RechargeLongRest && (r.Recharge == models.RechargeLongRest || r.Recharge == models.RechargeDawn):
+			result[i].Current = r.Max
+			recharged = append(recharged, r.Name)
+		}
+	}
+
+	return result, recharged
+}
+
+// recoverHitDice recupera la mitad del total de dados de golpe (mínimo 1),
+// empezando por los dados más grandes
+func recoverHitDice(pools []models.HitDicePool) []models.HitDicePool {
+	result := make([]models.HitDicePool, len(pools))
+	copy(result, pools)
+
+	total := 0
+	for _, p := range result {
+		total += p.Max
+	}
+	toRecover := total / 2
+	if toRecover < 1 {
+		toRecover = 1
+	}
+
+	order := make([]int, len(result))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return result[order[a]].Die > result[order[b]].Die })
+
+	for _, i := range order {
+		if toRecover == 0 {
+			break
+		}
+		missing := result[i].Max - result[i].Current
+		gain := minInt(missing, toRecover)
+		result[i].Current += gain
+		toRecover -= gain
+	}
+
+	return result
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,209 @@
+// backend/internal/handlers/rest.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// DESCANSOS Y USOS DE RASGOS
+// ===========================
+
+// RestCharacter - Aplicar un descanso corto o largo al personaje
+// Corto: recarga rasgos, recursos y objetos "short". Largo: recarga todo (incluidas las cargas "al amanecer"),
+// restaura HP y la mitad de los dados de golpe.
+func (h *Handler) RestCharacter(c *gin.Context) {
+	uid := c.GetString("uid")
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.RestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+	recharged := []string{}
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		recharged = recharged[:0]
+
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+		// Copia independiente del estado previo para el historial
+		var before models.Character
+		if err := charDoc.DataTo(&before); err != nil {
+			return err
+		}
+
+		items, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", charID))
+		if err != nil {
+			return err
+		}
+
+		features := make([]models.Feature, len(character.Features))
+		copy(features, character.Features)
+		for i, f := range features {
+			if f.Uses == nil || f.Uses.Current >= f.Uses.Max {
+				continue
+			}
+			if req.Type == models.RechargeLongRest || f.Uses.Recharge == models.RechargeShortRest {
+				uses := *f.Uses
+				uses.Current = uses.Max
+				features[i].Uses = &uses
+				recharged = append(recharged, f.Name)
+			}
+		}
+		character.Features = features
+
+		resources, rechargedResources := rechargeResources(character.Resources, req.Type)
+		character.Resources = resources
+		recharged = append(recharged, rechargedResources...)
+
+		// Objetos mágicos: el amanecer ocurre durante el descanso largo
+		rechargedItems, itemNames := rechargeItemsOnRest(items, req.Type)
+		recharged = append(recharged, itemNames...)
+		now := time.Now()
+		for _, item := range rechargedItems {
+			if err := tx.Update(h.db.Collection("inventory_items").Doc(item.ID), []firestore.Update{
+				{Path: "magicData", Value: item.MagicData},
+				{Path: "updatedAt", Value: now},
+			}); err != nil {
+				return err
+			}
+		}
+
+		updates := []firestore.Update{
+			{Path: "features", Value: features},
+			{Path: "resources", Value: resources},
+			{Path: "updatedAt", Value: now},
+		}
+
+		if req.Type == models.RechargeLongRest {
+			character.CurrentHP = character.MaxHP
+			character.TemporaryHP = 0
+			character.DeathSaves = models.DeathSaves{}
+			character.HitDice = recoverHitDice(character.HitDice)
+			updates = append(updates,
+				firestore.Update{Path: "currentHp", Value: character.MaxHP},
+				firestore.Update{Path: "temporaryHp", Value: 0},
+				firestore.Update{Path: "deathSaves", Value: character.DeathSaves},
+				firestore.Update{Path: "hitDice", Value: character.HitDice},
+			)
+		}
+
+		if err := tx.Update(charRef, updates); err != nil {
+			return err
+		}
+		after := characterAfterUpdates(&before, updates)
+		return h.recordCharacterVersionInTx(tx, &before, &after, uid, models.CharacterVersionRest)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error aplicando descanso"})
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+	h.invalidateCharacterCache(ctx, charID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Descanso aplicado",
+		"type":      req.Type,
+		"recharged": recharged,
+		"character": character,
+	})
+}
+
+// UseFeature - Gastar usos de un rasgo con usos limitados
+func (h *Handler) UseFeature(c *gin.Context) {
+	charID := c.Param("charId")
+	featureID := c.Param("featureId")
+	ctx := context.Background()
+
+	var req models.UseFeatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = 1
+	}
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+	var used models.Feature
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return err
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+
+		index := -1
+		for i, f := range character.Features {
+			if f.ID == featureID {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return errFeatureNotFound
+		}
+
+		feature := character.Features[index]
+		if feature.Uses == nil {
+			return errFeatureNotLimited
+		}
+		if feature.Uses.Current < amount {
+			return errNotEnoughResource
+		}
+
+		uses := *feature.Uses
+		uses.Current -= amount
+		feature.Uses = &uses
+		character.Features[index] = feature
+		used = feature
+
+		return tx.Update(charRef, []firestore.Update{
+			{Path: "features", Value: character.Features},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errFeatureNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errFeatureNotLimited), errors.Is(err, errNotEnoughResource):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error usando rasgo"})
+		}
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+character.CampaignID)
+
+	c.JSON(http.StatusOK, used)
+}
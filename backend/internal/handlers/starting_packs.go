@@ -0,0 +1,204 @@
+// backend/internal/handlers/starting_packs.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// EQUIPO INICIAL
+// ===========================
+
+var errStartingPackNotFound = errors.New("paquete de equipo inicial no encontrado")
+
+// startingPacks - paquetes de equipo del SRD y equipo inicial por clase (opciones por
+// defecto del SRD). Los items se resuelven con el compendio, así que el homebrew de la
+// campaña puede reemplazar cualquiera de ellos usando el mismo slug.
+var startingPacks = []models.StartingPack{
+	{ID: "paquete-explorador", Name: "Paquete de explorador", Items: []models.StartingPackItem{
+		{Slug: "backpack", Quantity: 1}, {Slug: "bedroll", Quantity: 1}, {Slug: "mess-kit", Quantity: 1},
+		{Slug: "tinderbox", Quantity: 1}, {Slug: "torch", Quantity: 10}, {Slug: "rations-1-day", Quantity: 10},
+		{Slug: "waterskin", Quantity: 1}, {Slug: "rope-hempen-50-feet", Quantity: 1},
+	}},
+	{ID: "paquete-mazmorras", Name: "Paquete de explorador de mazmorras", Items: []models.StartingPackItem{
+		{Slug: "backpack", Quantity: 1}, {Slug: "crowbar", Quantity: 1}, {Slug: "hammer", Quantity: 1},
+		{Slug: "piton", Quantity: 10}, {Slug: "torch", Quantity: 10}, {Slug: "tinderbox", Quantity: 1},
+		{Slug: "rations-1-day", Quantity: 10}, {Slug: "waterskin", Quantity: 1}, {Slug: "rope-hempen-50-feet", Quantity: 1},
+	}},
+	{ID: "paquete-sacerdote", Name: "Paquete de sacerdote", Items: []models.StartingPackItem{
+		{Slug: "backpack", Quantity: 1}, {Slug: "blanket", Quantity: 1}, {Slug: "candle", Quantity: 10},
+		{Slug: "tinderbox", Quantity: 1}, {Slug: "clothes-common", Quantity: 1}, {Slug: "rations-1-day", Quantity: 2},
+		{Slug: "waterskin", Quantity: 1},
+	}},
+	{ID: "paquete-erudito", Name: "Paquete de erudito", Items: []models.StartingPackItem{
+		{Slug: "backpack", Quantity: 1}, {Slug: "book", Quantity: 1}, {Slug: "ink-1-ounce-bottle", Quantity: 1},
+		{Slug: "ink-pen", Quantity: 1}, {Slug: "parchment-one-sheet", Quantity: 10},
+	}},
+	{ID: "paquete-ladron", Name: "Paquete de ladrón", Items: []models.StartingPackItem{
+		{Slug: "backpack", Quantity: 1}, {Slug: "ball-bearings-bag-of-1000", Quantity: 1}, {Slug: "bell", Quantity: 1},
+		{Slug: "candle", Quantity: 5}, {Slug: "crowbar", Quantity: 1}, {Slug: "hammer", Quantity: 1},
+		{Slug: "piton", Quantity: 10}, {Slug: "lantern-hooded", Quantity: 1}, {Slug: "oil-flask", Quantity: 2},
+		{Slug: "rations-1-day", Quantity: 5}, {Slug: "tinderbox", Quantity: 1}, {Slug: "waterskin", Quantity: 1},
+		{Slug: "rope-hempen-50-feet", Quantity: 1},
+	}},
+	{ID: "paquete-artista", Name: "Paquete de artista", Items: []models.StartingPackItem{
+		{Slug: "backpack", Quantity: 1}, {Slug: "bedroll", Quantity: 1}, {Slug: "clothes-fine", Quantity: 2},
+		{Slug: "candle", Quantity: 5}, {Slug: "rations-1-day", Quantity: 5}, {Slug: "waterskin", Quantity: 1},
+		{Slug: "disguise-kit", Quantity: 1},
+	}},
+
+	{ID: "bárbaro", Name: "Equipo inicial de bárbaro", Class: "bárbaro", Includes: []string{"paquete-explorador"}, Items: []models.StartingPackItem{
+		{Slug: "greataxe", Quantity: 1}, {Slug: "handaxe", Quantity: 2}, {Slug: "javelin", Quantity: 4},
+	}},
+	{ID: "bardo", Name: "Equipo inicial de bardo", Class: "bardo", Includes: []string{"paquete-artista"}, Items: []models.StartingPackItem{
+		{Slug: "rapier", Quantity: 1}, {Slug: "lute", Quantity: 1}, {Slug: "leather", Quantity: 1}, {Slug: "dagger", Quantity: 1},
+	}},
+	{ID: "clérigo", Name: "Equipo inicial de clérigo", Class: "clérigo", Includes: []string{"paquete-sacerdote"}, Items: []models.StartingPackItem{
+		{Slug: "mace", Quantity: 1}, {Slug: "scale-mail", Quantity: 1}, {Slug: "crossbow-light", Quantity: 1},
+		{Slug: "crossbow-bolt", Quantity: 20}, {Slug: "shield", Quantity: 1}, {Slug: "amulet", Quantity: 1},
+	}},
+	{ID: "druida", Name: "Equipo inicial de druida", Class: "druida", Includes: []string{"paquete-explorador"}, Items: []models.StartingPackItem{
+		{Slug: "shield", Quantity: 1}, {Slug: "scimitar", Quantity: 1}, {Slug: "leather", Quantity: 1},
+		{Slug: "sprig-of-mistletoe", Quantity: 1},
+	}},
+	{ID: "guerrero", Name: "Equipo inicial de guerrero", Class: "guerrero", Includes: []string{"paquete-mazmorras"}, Items: []models.StartingPackItem{
+		{Slug: "chain-mail", Quantity: 1}, {Slug: "longsword", Quantity: 1}, {Slug: "shield", Quantity: 1},
+		{Slug: "crossbow-light", Quantity: 1}, {Slug: "crossbow-bolt", Quantity: 20},
+	}},
+	{ID: "monje", Name: "Equipo inicial de monje", Class: "monje", Includes: []string{"paquete-explorador"}, Items: []models.StartingPackItem{
+		{Slug: "shortsword", Quantity: 1}, {Slug: "dart", Quantity: 10},
+	}},
+	{ID: "paladín", Name: "Equipo inicial de paladín", Class: "paladín", Includes: []string{"paquete-sacerdote"}, Items: []models.StartingPackItem{
+		{Slug: "longsword", Quantity: 1}, {Slug: "shield", Quantity: 1}, {Slug: "javelin", Quantity: 5},
+		{Slug: "chain-mail", Quantity: 1}, {Slug: "amulet", Quantity: 1},
+	}},
+	{ID: "explorador", Name: "Equipo inicial de explorador", Class: "explorador", Includes: []string{"paquete-explorador"}, Items: []models.StartingPackItem{
+		{Slug: "scale-mail", Quantity: 1}, {Slug: "shortsword", Quantity: 2}, {Slug: "longbow", Quantity: 1},
+		{Slug: "quiver", Quantity: 1}, {Slug: "arrow", Quantity: 20},
+	}},
+	{ID: "pícaro", Name: "Equipo inicial de pícaro", Class: "pícaro", Includes: []string{"paquete-ladron"}, Items: []models.StartingPackItem{
+		{Slug: "rapier", Quantity: 1}, {Slug: "shortbow", Quantity: 1}, {Slug: "quiver", Quantity: 1},
+		{Slug: "arrow", Quantity: 20}, {Slug: "leather", Quantity: 1}, {Slug: "dagger", Quantity: 2},
+		{Slug: "thieves-tools", Quantity: 1},
+	}},
+	{ID: "hechicero", Name: "Equipo inicial de hechicero", Class: "hechicero", Includes: []string{"paquete-explorador"}, Items: []models.StartingPackItem{
+		{Slug: "crossbow-light", Quantity: 1}, {Slug: "crossbow-bolt", Quantity: 20},
+		{Slug: "component-pouch", Quantity: 1}, {Slug: "dagger", Quantity: 2},
+	}},
+	{ID: "brujo", Name: "Equipo inicial de brujo", Class: "brujo", Includes: []string{"paquete-erudito"}, Items: []models.StartingPackItem{
+		{Slug: "crossbow-light", Quantity: 1}, {Slug: "crossbow-bolt", Quantity: 20}, {Slug: "component-pouch", Quantity: 1},
+		{Slug: "leather", Quantity: 1}, {Slug: "quarterstaff", Quantity: 1}, {Slug: "dagger", Quantity: 2},
+	}},
+	{ID: "mago", Name: "Equipo inicial de mago", Class: "mago", Includes: []string{"paquete-erudito"}, Items: []models.StartingPackItem{
+		{Slug: "quarterstaff", Quantity: 1}, {Slug: "component-pouch", Quantity: 1}, {Slug: "spellbook", Quantity: 1},
+	}},
+}
+
+// GetStartingPacks - Listar los paquetes de equipo inicial (?class filtra por clase)
+func (h *Handler) GetStartingPacks(c *gin.Context) {
+	class := normalizeClass(c.Query("class"))
+
+	packs := []models.StartingPack{}
+	for _, pack := range startingPacks {
+		if class == "" || pack.Class == class {
+			packs = append(packs, pack)
+		}
+	}
+	c.JSON(http.StatusOK, packs)
+}
+
+// ApplyStartingPack - Agregar un paquete de equipo inicial al inventario en un solo batch.
+// Sin packId se usa el paquete de la clase del personaje.
+func (h *Handler) ApplyStartingPack(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.ApplyStartingPackRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	packID := req.PackID
+	if packID == "" {
+		charDoc, err := h.db.Collection("characters").Doc(charID).Get(ctx)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+			return
+		}
+		var character models.Character
+		if err := charDoc.DataTo(&character); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando personaje"})
+			return
+		}
+		packID = normalizeClass(character.Class)
+	}
+
+	creates, err := startingPackRequests(packID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.applyBulkItems(ctx, charID, c.GetString("uid"), creates, nil)
+	if err != nil {
+		respondBulkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ===========================
+// HELPERS DE EQUIPO INICIAL
+// ===========================
+
+func findStartingPack(id string) (models.StartingPack, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, pack := range startingPacks {
+		if pack.ID == id {
+			return pack, true
+		}
+	}
+	return models.StartingPack{}, false
+}
+
+// startingPackRequests expande el paquete (y los que incluye) en requests por slug
+func startingPackRequests(id string) ([]models.CreateItemRequest, error) {
+	pack, ok := findStartingPack(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errStartingPackNotFound, id)
+	}
+
+	requests := []models.CreateItemRequest{}
+	for _, included := range pack.Includes {
+		more, err := startingPackRequests(included)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, more...)
+	}
+	for _, item := range pack.Items {
+		requests = append(requests, models.CreateItemRequest{Open5eSlug: item.Slug, Quantity: item.Quantity})
+	}
+	return requests, nil
+}
+
+// normalizeClass pasa el nombre de clase a minúsculas y al español ("Fighter" -> "guerrero")
+func normalizeClass(class string) string {
+	class = strings.ToLower(strings.TrimSpace(class))
+	if alias, ok := classAliases[class]; ok {
+		return alias
+	}
+	return class
+}
@@ -0,0 +1,402 @@
+// backend/internal/handlers/transfers.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// TRANSFERENCIAS ENTRE PERSONAJES
+// ===========================
+
+var (
+	errTransferItemNotFound  = errors.New("item no encontrado")
+	errTransferTargetMissing = errors.New("personaje destino no encontrado")
+	errTransferSameCharacter = errors.New("el origen y el destino son el mismo personaje")
+	errTransferOtherCampaign = errors.New("los personajes deben estar en la misma campaña")
+	errTransferForbidden     = errors.New("solo el dueño del personaje o el DM pueden transferir")
+	errTransferNotEnough     = errors.New("cantidad insuficiente para transferir")
+	errTransferTargetFull    = errors.New("el personaje destino alcanzó el límite de items")
+	errTransferEmpty         = errors.New("no hay monedas para transferir")
+)
+
+// TransferItem - Mover cantidad de un item al inventario de otro personaje de la campaña
+func (h *Handler) TransferItem(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	itemID := c.Param("itemId")
+	ctx := context.Background()
+
+	var req models.TransferItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	itemRef := h.db.Collection("inventory_items").Doc(itemID)
+	var source models.InventoryItem
+	var received models.InventoryItem
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		itemDoc, err := tx.Get(itemRef)
+		if err != nil {
+			return errTransferItemNotFound
+		}
+		if err := itemDoc.DataTo(&source); err != nil {
+			return err
+		}
+		if source.CharacterID == req.ToCharacterID {
+			return errTransferSameCharacter
+		}
+
+		from, to, err := h.transferParties(tx, uid, source.CharacterID, req.ToCharacterID)
+		if err != nil {
+			return err
+		}
+		if source.Quantity < req.Quantity {
+			return errTransferNotEnough
+		}
+		if err := containerIsEmpty(tx, h.db, source); err != nil {
+			return err
+		}
+
+		targetItems, err := characterItemsInTx(tx, h.db.Collection("inventory_items").Where("characterId", "==", to.ID))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		// El item llega suelto: solo se apila con pilas fuera de contenedores
+		incoming := source
+		incoming.ContainerID = ""
+		stack := findStack(targetItems, incoming, req.Quantity)
+		if stack == -1 && len(targetItems) >= MAX_ITEMS_PER_CHARACTER {
+			return errTransferTargetFull
+		}
+
+		// Origen: descontar o eliminar
+		if source.Quantity == req.Quantity {
+			if err := tx.Delete(itemRef); err != nil {
+				return err
+			}
+		} else if err := tx.Update(itemRef, []firestore.Update{
+			{Path: "quantity", Value: source.Quantity - req.Quantity},
+			{Path: "updatedAt", Value: now},
+		}); err != nil {
+			return err
+		}
+
+		// Destino: apilar o crear (el item llega sin equipar ni sintonizar)
+		if stack != -1 {
+			received = targetItems[stack]
+			received.Quantity += req.Quantity
+			received.UpdatedAt = now
+			if err := tx.Update(h.db.Collection("inventory_items").Doc(received.ID), []firestore.Update{
+				{Path: "quantity", Value: received.Quantity},
+				{Path: "updatedAt", Value: now},
+			}); err != nil {
+				return err
+			}
+		} else {
+			newRef := h.db.Collection("inventory_items").NewDoc()
+			received = source
+			received.ID = newRef.ID
+			received.CharacterID = to.ID
+			received.Quantity = req.Quantity
+			received.Equipped = false
+			received.Attuned = false
+			received.ContainerID = ""
+			received.CreatedAt = now
+			received.UpdatedAt = now
+			if err := tx.Set(newRef, received); err != nil {
+				return err
+			}
+		}
+
+		auditRef := h.db.Collection("inventory_transfers").NewDoc()
+		return tx.Set(auditRef, models.InventoryTransfer{
+			ID:              auditRef.ID,
+			CampaignID:      from.CampaignID,
+			Kind:            models.TransferKindItem,
+			FromCharacterID: from.ID,
+			ToCharacterID:   to.ID,
+			PerformedBy:     uid,
+			ItemName:        source.Name,
+			Quantity:        req.Quantity,
+			CreatedAt:       now,
+		})
+	})
+
+	if err != nil {
+		h.respondTransferError(c, err, "Error transfiriendo item")
+		return
+	}
+
+	if source.Equipped && source.ArmorData != nil && source.Quantity == req.Quantity {
+		h.refreshArmorClass(ctx, source.CharacterID, uid)
+	}
+	h.invalidateCharacterCache(ctx, source.CharacterID)
+	h.invalidateCharacterCache(ctx, req.ToCharacterID)
+
+	c.JSON(http.StatusOK, received)
+}
+
+// TransferCurrency - Mover monedas de un personaje a otro de la misma campaña
+func (h *Handler) TransferCurrency(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.TransferCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	amount := models.Currency{
+		Copper:   req.Copper,
+		Silver:   req.Silver,
+		Electrum: req.Electrum,
+		Gold:     req.Gold,
+		Platinum: req.Platinum,
+	}
+	if amount == (models.Currency{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errTransferEmpty.Error()})
+		return
+	}
+	if charID == req.ToCharacterID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errTransferSameCharacter.Error()})
+		return
+	}
+
+	fromRef := h.db.Collection("currencies").Doc(charID)
+	toRef := h.db.Collection("currencies").Doc(req.ToCharacterID)
+	var fromCurrency, toCurrency models.Currency
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		from, to, err := h.transferParties(tx, uid, charID, req.ToCharacterID)
+		if err != nil {
+			return err
+		}
+
+		if fromCurrency, err = currencyInTx(tx, fromRef); err != nil {
+			return err
+		}
+		if toCurrency, err = currencyInTx(tx, toRef); err != nil {
+			return err
+		}
+
+		if fromCurrency.Copper < amount.Copper || fromCurrency.Silver < amount.Silver || fromCurrency.Electrum < amount.Electrum ||
+			fromCurrency.Gold < amount.Gold || fromCurrency.Platinum < amount.Platinum {
+			return errTransferNotEnough
+		}
+
+		fromBefore, toBefore := fromCurrency, toCurrency
+		fromCurrency = addCurrency(fromCurrency, amount, -1)
+		toCurrency = addCurrency(toCurrency, amount, 1)
+		if err := validateCurrency(toCurrency); err != nil {
+			return err
+		}
+
+		if err := tx.Set(fromRef, fromCurrency); err != nil {
+			return err
+		}
+		if err := tx.Set(toRef, toCurrency); err != nil {
+			return err
+		}
+
+		if _, err := h.ledgerEntryInTx(tx, from, uid, "Transferencia a "+to.Name, "", fromBefore, fromCurrency); err != nil {
+			return err
+		}
+		if _, err := h.ledgerEntryInTx(tx, to, uid, "Transferencia de "+from.Name, "", toBefore, toCurrency); err != nil {
+			return err
+		}
+
+		auditRef := h.db.Collection("inventory_transfers").NewDoc()
+		return tx.Set(auditRef, models.InventoryTransfer{
+			ID:              auditRef.ID,
+			CampaignID:      from.CampaignID,
+			Kind:            models.TransferKindCurrency,
+			FromCharacterID: from.ID,
+			ToCharacterID:   to.ID,
+			PerformedBy:     uid,
+			Currency:        &amount,
+			CreatedAt:       time.Now(),
+		})
+	})
+
+	if err != nil {
+		h.respondTransferError(c, err, "Error transfiriendo monedas")
+		return
+	}
+
+	h.invalidateCharacterCache(ctx, charID)
+	h.invalidateCharacterCache(ctx, req.ToCharacterID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": fromCurrency,
+		"to":   toCurrency,
+	})
+}
+
+// GetCampaignTransfers - Registro de transferencias de la campaña
+func (h *Handler) GetCampaignTransfers(c *gin.Context) {
+	campaignID := c.Param("id")
+	ctx := context.Background()
+
+	iter := h.db.Collection("inventory_transfers").
+		Where("campaignId", "==", campaignID).
+		OrderBy("createdAt", firestore.Desc).
+		Limit(100).
+		Documents(ctx)
+
+	var transfers []models.InventoryTransfer
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo transferencias"})
+			return
+		}
+
+		var transfer models.InventoryTransfer
+		if err := doc.DataTo(&transfer); err != nil {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if transfers == nil {
+		transfers = []models.InventoryTransfer{}
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// ===========================
+// HELPERS DE TRANSFERENCIAS
+// ===========================
+
+// transferParties lee origen y destino, y valida campaña compartida y permisos (dueño del origen o DM)
+func (h *Handler) transferParties(tx *firestore.Transaction, uid, fromID, toID string) (*models.Character, *models.Character, error) {
+	var from, to models.Character
+
+	fromDoc, err := tx.Get(h.db.Collection("characters").Doc(fromID))
+	if err != nil {
+		return nil, nil, errVaultCharacterNotFound
+	}
+	if err := fromDoc.DataTo(&from); err != nil {
+		return nil, nil, err
+	}
+
+	toDoc, err := tx.Get(h.db.Collection("characters").Doc(toID))
+	if err != nil {
+		return nil, nil, errTransferTargetMissing
+	}
+	if err := toDoc.DataTo(&to); err != nil {
+		return nil, nil, err
+	}
+
+	if from.CampaignID == "" || from.CampaignID != to.CampaignID {
+		return nil, nil, errTransferOtherCampaign
+	}
+
+	if from.UserID != uid {
+		campaignDoc, err := tx.Get(h.db.Collection("events").Doc(from.CampaignID))
+		if err != nil {
+			return nil, nil, errTransferForbidden
+		}
+		var campaign models.Campaign
+		if err := campaignDoc.DataTo(&campaign); err != nil || campaign.DmID != uid {
+			return nil, nil, errTransferForbidden
+		}
+	}
+
+	return &from, &to, nil
+}
+
+func (h *Handler) respondTransferError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, errTransferItemNotFound), errors.Is(err, errTransferTargetMissing), errors.Is(err, errVaultCharacterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errTransferForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, errTransferSameCharacter), errors.Is(err, errTransferOtherCampaign),
+		errors.Is(err, errTransferNotEnough), errors.Is(err, errTransferTargetFull), errors.Is(err, errCurrencyOverflow),
+		errors.Is(err, errContainerNotEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// findStack busca en items una pila compatible con item en la que quepan quantity unidades:
+// en el mismo contenedor, con el mismo Open5eSlug o el mismo nombre y tipo si no tiene
+// datos de arma/armadura. Los contenedores no se apilan (cada uno tiene su capacidad) y
+// los items equipados o sintonizados tampoco: la copia nueva llega sin equipar.
+func findStack(items []models.InventoryItem, item models.InventoryItem, quantity int) int {
+	if hasCharges(item.MagicData) || item.ContainerData != nil {
+		return -1
+	}
+	for i, existing := range items {
+		if hasCharges(existing.MagicData) || existing.ContainerData != nil || existing.Equipped || existing.Attuned ||
+			existing.ContainerID != item.ContainerID || existing.Quantity+quantity > MAX_ITEM_QUANTITY {
+			continue
+		}
+		if item.Open5eSlug != "" {
+			if existing.Open5eSlug == item.Open5eSlug {
+				return i
+			}
+			continue
+		}
+		if existing.Name == item.Name && existing.Type == item.Type &&
+			existing.WeaponData == nil && existing.ArmorData == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// currencyInTx lee las monedas de un personaje; si el documento no existe devuelve 0s
+func currencyInTx(tx *firestore.Transaction, ref *firestore.DocumentRef) (models.Currency, error) {
+	var currency models.Currency
+	doc, err := tx.Get(ref)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return currency, nil
+		}
+		return currency, err
+	}
+	err = doc.DataTo(&currency)
+	return currency, err
+}
+
+// addCurrency suma (sign 1) o resta (sign -1) monedas por denominación
+func addCurrency(base, amount models.Currency, sign int) models.Currency {
+	base.Copper += sign * amount.Copper
+	base.Silver += sign * amount.Silver
+	base.Electrum += sign * amount.Electrum
+	base.Gold += sign * amount.Gold
+	base.Platinum += sign * amount.Platinum
+	return base
+}
@@ -0,0 +1,400 @@
+// backend/internal/handlers/vault.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+
+	"github.com/FranMaggi73/dm-events-backend/internal/models"
+)
+
+// ===========================
+// BAÚL DE PERSONAJES
+// ===========================
+// Un personaje con campaignId vacío vive en el baúl de su dueño y
+// sobrevive a las campañas: se puede vincular, desvincular o clonar.
+
+var (
+	errCharacterInCampaign    = errors.New("el personaje ya está en una campaña")
+	errCharacterNotAttached   = errors.New("el personaje no está en ninguna campaña")
+	errCharacterInEncounter   = errors.New("el personaje está en un encuentro")
+	errNotCampaignMember      = errors.New("no eres miembro de esta campaña")
+	errAlreadyHasCharacter    = errors.New("ya tienes un personaje en esta campaña")
+	errVaultCharacterNotFound = errors.New("personaje no encontrado")
+)
+
+// GetVaultCharacters - Listar los personajes del baúl del usuario
+func (h *Handler) GetVaultCharacters(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	ctx := context.Background()
+
+	iter := h.db.Collection("characters").
+		Where("userId", "==", uid).
+		Where("campaignId", "==", "").
+		Documents(ctx)
+
+	var characters []models.Character
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo personajes"})
+			return
+		}
+
+		var char models.Character
+		if err := doc.DataTo(&char); err != nil {
+			continue
+		}
+		characters = append(characters, char)
+	}
+
+	if characters == nil {
+		characters = []models.Character{}
+	}
+
+	sort.Slice(characters, func(i, j int) bool {
+		return characters[i].UpdatedAt.After(characters[j].UpdatedAt)
+	})
+
+	c.JSON(http.StatusOK, characters)
+}
+
+// CreateVaultCharacter - Crear un personaje directamente en el baúl
+func (h *Handler) CreateVaultCharacter(c *gin.Context) {
+	uid := c.GetString("uid")
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	ctx := context.Background()
+
+	var req models.CreateCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateBackgroundSkills(req.Background, req.Skills); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charRef := h.db.Collection("characters").NewDoc()
+	character := buildCharacter(req, charRef.ID, "", uid)
+
+	if _, err := charRef.Set(ctx, character); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando personaje"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, character)
+}
+
+// AttachCharacter - Vincular un personaje del baúl a una campaña de la que el usuario es miembro
+func (h *Handler) AttachCharacter(c *gin.Context) {
+	uid := c.GetString("uid")
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	var req models.AttachCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return errVaultCharacterNotFound
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+		if character.CampaignID != "" {
+			return errCharacterInCampaign
+		}
+
+		memberIter := tx.Documents(h.db.Collection("event_members").
+			Where("campaignId", "==", req.CampaignID).
+			Where("userId", "==", uid).
+			Limit(1))
+		if _, err := memberIter.Next(); err == iterator.Done {
+			return errNotCampaignMember
+		}
+
+		existingIter := tx.Documents(h.db.Collection("characters").
+			Where("campaignId", "==", req.CampaignID).
+			Where("userId", "==", uid).
+			Limit(1))
+		if _, err := existingIter.Next(); err != iterator.Done {
+			return errAlreadyHasCharacter
+		}
+
+		itemRefs, err := characterItemRefs(tx.Documents(h.db.Collection("inventory_items").
+			Where("characterId", "==", charID)))
+		if err != nil {
+			return err
+		}
+
+		character.CampaignID = req.CampaignID
+		character.UpdatedAt = time.Now()
+		return moveCharacterInTx(tx, charRef, itemRefs, req.CampaignID, character.UpdatedAt)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errVaultCharacterNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errNotCampaignMember):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, errCharacterInCampaign), errors.Is(err, errAlreadyHasCharacter):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error vinculando personaje"})
+		}
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+req.CampaignID)
+	h.invalidateCharacterCache(ctx, charID)
+
+	c.JSON(http.StatusOK, character)
+}
+
+// DetachCharacter - Devolver un personaje de su campaña al baúl del dueño
+func (h *Handler) DetachCharacter(c *gin.Context) {
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	charRef := h.db.Collection("characters").Doc(charID)
+	var character models.Character
+	var previousCampaign string
+
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		charDoc, err := tx.Get(charRef)
+		if err != nil {
+			return errVaultCharacterNotFound
+		}
+		if err := charDoc.DataTo(&character); err != nil {
+			return err
+		}
+		if character.CampaignID == "" {
+			return errCharacterNotAttached
+		}
+
+		combatantIter := tx.Documents(h.db.Collection("combatants").
+			Where("characterId", "==", charID).
+			Limit(1))
+		if _, err := combatantIter.Next(); err != iterator.Done {
+			return errCharacterInEncounter
+		}
+
+		itemRefs, err := characterItemRefs(tx.Documents(h.db.Collection("inventory_items").
+			Where("characterId", "==", charID)))
+		if err != nil {
+			return err
+		}
+
+		previousCampaign = character.CampaignID
+		character.CampaignID = ""
+		character.UpdatedAt = time.Now()
+		return moveCharacterInTx(tx, charRef, itemRefs, "", character.UpdatedAt)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errVaultCharacterNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errCharacterNotAttached), errors.Is(err, errCharacterInEncounter):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error desvinculando personaje"})
+		}
+		return
+	}
+
+	h.invalidatePattern(ctx, "characters:"+previousCampaign)
+	h.invalidateCharacterCache(ctx, charID)
+
+	c.JSON(http.StatusOK, character)
+}
+
+// CloneCharacter - Copiar un personaje (hoja, inventario y monedas) al baúl
+func (h *Handler) CloneCharacter(c *gin.Context) {
+	uid := c.GetString("uid")
+	charID := c.Param("charId")
+	ctx := context.Background()
+
+	source, items, currency, err := h.loadCharacterSheet(ctx, charID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+		return
+	}
+
+	if len(items) > maxImportedItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El personaje tiene demasiados items para clonarlo"})
+		return
+	}
+
+	now := time.Now()
+	cloneRef := h.db.Collection("characters").NewDoc()
+	clone := *source
+	clone.ID = cloneRef.ID
+	clone.CampaignID = ""
+	clone.UserID = uid
+	clone.Conditions = []string{}
+	clone.CreatedAt = now
+	clone.UpdatedAt = now
+
+	err = h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Set(cloneRef, clone); err != nil {
+			return err
+		}
+
+		itemRefs := reassignItemIDs(items, h.db.Collection("inventory_items"))
+		for i, item := range items {
+			itemRef := itemRefs[i]
+			item.CharacterID = cloneRef.ID
+			item.CampaignID = ""
+			item.CreatedAt = now
+			item.UpdatedAt = now
+			if err := tx.Set(itemRef, item); err != nil {
+				return err
+			}
+		}
+
+		return tx.Set(h.db.Collection("currencies").Doc(cloneRef.ID), currency)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error clonando personaje"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, clone)
+}
+
+// ===========================
+// HELPERS DEL BAÚL
+// ===========================
+
+// characterItemRefs lee las referencias de los items de un personaje
+func characterItemRefs(iter *firestore.DocumentIterator) ([]*firestore.DocumentRef, error) {
+	var refs []*firestore.DocumentRef
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, doc.Ref)
+	}
+	return refs, nil
+}
+
+// moveCharacterInTx cambia la campaña del personaje y de sus items
+func moveCharacterInTx(tx *firestore.Transaction, charRef *firestore.DocumentRef, itemRefs []*firestore.DocumentRef, campaignID string, now time.Time) error {
+	if err := tx.Update(charRef, []firestore.Update{
+		{Path: "campaignId", Value: campaignID},
+		{Path: "updatedAt", Value: now},
+	}); err != nil {
+		return err
+	}
+
+	for _, ref := range itemRefs {
+		if err := tx.Update(ref, []firestore.Update{
+			{Path: "campaignId", Value: campaignID},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveCharactersToVault devuelve al baúl (en batches) los personajes de la consulta
+// junto con sus items. Se usa al eliminar una campaña o expulsar a un jugador.
+func (h *Handler) moveCharactersToVault(ctx context.Context, query firestore.Query) int {
+	batch := h.db.Batch()
+	count := 0
+	moved := 0
+	now := time.Now()
+
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			log.Printf("Error en batch commit del baúl: %v", err)
+		}
+		batch = h.db.Batch()
+		count = 0
+	}
+
+	iter := query.Documents(ctx)
+	for {
+		charDoc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Error iterando personajes: %v", err)
+			break
+		}
+
+		batch.Update(charDoc.Ref, []firestore.Update{
+			{Path: "campaignId", Value: ""},
+			{Path: "conditions", Value: []string{}},
+			{Path: "updatedAt", Value: now},
+		})
+		count++
+		moved++
+
+		itemsIter := h.db.Collection("inventory_items").
+			Where("characterId", "==", charDoc.Ref.ID).
+			Documents(ctx)
+		for {
+			itemDoc, err := itemsIter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				break
+			}
+			batch.Update(itemDoc.Ref, []firestore.Update{
+				{Path: "campaignId", Value: ""},
+			})
+			count++
+			if count >= 400 {
+				flush()
+			}
+		}
+
+		if count >= 400 {
+			flush()
+		}
+	}
+	flush()
+
+	return moved
+}
@@ -180,6 +180,13 @@ func (pm *PermissionsMiddleware) RequireCharacterOwnerOrDM() gin.HandlerFunc {
 			return
 		}
 
+		// Los personajes del baúl (sin campaña) solo los gestiona su dueño
+		if character.CampaignID == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para modificar este personaje"})
+			c.Abort()
+			return
+		}
+
 		// Si no es el dueño, verificar si es el DM
 		campaign, _, found := pm.cache.GetCampaign(character.CampaignID)
 		if !found {
@@ -213,6 +220,49 @@ func (pm *PermissionsMiddleware) RequireCharacterOwnerOrDM() gin.HandlerFunc {
 	}
 }
 
+// RequireCharacterOwner verifica que el usuario sea el dueño del personaje
+// (mover entre campañas y clonar no lo puede hacer el DM)
+func (pm *PermissionsMiddleware) RequireCharacterOwner() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid := c.GetString("uid")
+		if uid == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "No autenticado"})
+			c.Abort()
+			return
+		}
+
+		charID := c.Param("charId")
+		if charID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID de personaje requerido"})
+			c.Abort()
+			return
+		}
+
+		charDoc, err := pm.db.Collection("characters").Doc(charID).Get(context.Background())
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Personaje no encontrado"})
+			c.Abort()
+			return
+		}
+
+		var character models.Character
+		if err := charDoc.DataTo(&character); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parseando personaje"})
+			c.Abort()
+			return
+		}
+
+		if character.UserID != uid {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Solo el dueño puede realizar esta acción"})
+			c.Abort()
+			return
+		}
+
+		c.Set("character", &character)
+		c.Next()
+	}
+}
+
 // RequireEncounterDM verifica que el usuario sea DM del encuentro
 func (pm *PermissionsMiddleware) RequireEncounterDM() gin.HandlerFunc {
 	return func(c *gin.Context) {
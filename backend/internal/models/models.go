@@ -27,6 +27,9 @@ type Campaign struct {
 	DmPhoto   string    `firestore:"dmPhoto" json:"dmPhoto"`
 	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
 	PlayerIDs []string  `firestore:"playerIds" json:"playerIds"`
+
+	// Modo de progresión: "xp" (default) o "milestone"
+	ProgressionMode string `firestore:"progressionMode,omitempty" json:"progressionMode,omitempty"`
 }
 
 type CreateCampaignRequest struct {
@@ -101,15 +104,86 @@ type Skill struct {
 	Expertise  bool   `firestore:"expertise" json:"expertise"`   // ¿Tiene expertise? (x2)
 }
 
+// Background representa el trasfondo del personaje
+type Background struct {
+	Name               string   `firestore:"name" json:"name" binding:"required,min=2,max=50"`
+	SkillProficiencies []string `firestore:"skillProficiencies" json:"skillProficiencies" binding:"max=4,dive,required,max=30"`
+	ToolProficiencies  []string `firestore:"toolProficiencies,omitempty" json:"toolProficiencies,omitempty" binding:"max=4,dive,max=50"`
+	Languages          []string `firestore:"languages,omitempty" json:"languages,omitempty" binding:"max=4,dive,max=30"`
+	Feature            string   `firestore:"feature,omitempty" json:"feature,omitempty" binding:"max=1000"`
+}
+
+// Feat representa una dote
+type Feat struct {
+	Name        string `firestore:"name" json:"name" binding:"required,min=2,max=100"`
+	Description string `firestore:"description,omitempty" json:"description,omitempty" binding:"max=2000"`
+	Source      string `firestore:"source,omitempty" json:"source,omitempty" binding:"max=50"` // Ej: "Nivel 4", "Humano variante"
+}
+
+const (
+	RechargeShortRest = "short"
+	RechargeLongRest  = "long"
+	RechargeDawn      = "dawn"
+	RechargeNone      = "none"
+)
+
+// LimitedUses representa los usos limitados de un rasgo
+type LimitedUses struct {
+	Max      int    `firestore:"max" json:"max" binding:"min=1,max=99"`
+	Current  int    `firestore:"current" json:"current" binding:"min=0,max=99"`
+	Recharge string `firestore:"recharge" json:"recharge" binding:"required,oneof=short long"` // "short" o "long"
+}
+
+// Feature representa un rasgo de clase, raza, trasfondo o dote
+type Feature struct {
+	ID          string       `firestore:"id" json:"id" binding:"max=50"`
+	Name        string       `firestore:"name" json:"name" binding:"required,min=2,max=100"`
+	Source      string       `firestore:"source" json:"source" binding:"required,oneof=class race background feat other"`
+	Level       int          `firestore:"level,omitempty" json:"level,omitempty" binding:"min=0,max=20"` // Nivel en el que se obtiene
+	Description string       `firestore:"description,omitempty" json:"description,omitempty" binding:"max=2000"`
+	Uses        *LimitedUses `firestore:"uses,omitempty" json:"uses,omitempty"`
+}
+
+// ResourceFormula calcula el máximo de un recurso: base + perLevel*nivel + mod(ability), con mínimo
+type ResourceFormula struct {
+	Base     int    `firestore:"base" json:"base" binding:"min=-10,max=100"`
+	PerLevel int    `firestore:"perLevel" json:"perLevel" binding:"min=0,max=10"` // Ki: 1, Imposición de manos: 5
+	Ability  string `firestore:"ability,omitempty" json:"ability,omitempty" binding:"omitempty,oneof=strength dexterity constitution intelligence wisdom charisma"`
+	Minimum  int    `firestore:"minimum" json:"minimum" binding:"min=0,max=100"`
+}
+
+// Resource representa un recurso de clase con contador (Furia, Ki, Inspiración bárdica...)
+type Resource struct {
+	ID       string           `firestore:"id" json:"id" binding:"max=50"`
+	Name     string           `firestore:"name" json:"name" binding:"required,min=2,max=50"`
+	Max      int              `firestore:"max" json:"max" binding:"min=0,max=999"` // Ignorado si hay fórmula
+	Current  int              `firestore:"current" json:"current" binding:"min=0,max=999"`
+	Recharge string           `firestore:"recharge" json:"recharge" binding:"required,oneof=short long dawn none"`
+	Formula  *ResourceFormula `firestore:"formula,omitempty" json:"formula,omitempty"`
+}
+
+// HitDicePool representa los dados de golpe de un tamaño (uno por clase en multiclase)
+type HitDicePool struct {
+	Die     int `firestore:"die" json:"die" binding:"required,oneof=6 8 10 12"`
+	Max     int `firestore:"max" json:"max" binding:"min=0,max=20"`
+	Current int `firestore:"current" json:"current" binding:"min=0,max=20"`
+}
+
 // Character - Modelo completo Nivel 1
 type Character struct {
 	ID         string `firestore:"id" json:"id"`
-	CampaignID string `firestore:"campaignId" json:"campaignId"`
+	CampaignID string `firestore:"campaignId" json:"campaignId"` // "" = en el baúl del usuario
 	UserID     string `firestore:"userId" json:"userId"`
 	Name       string `firestore:"name" json:"name"`
 	Class      string `firestore:"class" json:"class"`
 	Level      int    `firestore:"level" json:"level"`
 
+	// ===== ORIGEN =====
+	Race       string      `firestore:"race,omitempty" json:"race,omitempty"`
+	Subrace    string      `firestore:"subrace,omitempty" json:"subrace,omitempty"`
+	Background *Background `firestore:"background,omitempty" json:"background,omitempty"`
+	Alignment  string      `firestore:"alignment,omitempty" json:"alignment,omitempty"`
+
 	// ===== COMBAT STATS =====
 	MaxHP       int        `firestore:"maxHp" json:"maxHp"`
 	CurrentHP   int        `firestore:"currentHp" json:"currentHp"`
@@ -128,6 +202,24 @@ type Character struct {
 	SavingThrows     SavingThrows `firestore:"savingThrows" json:"savingThrows"`         // ✅ NUEVO
 	Skills           []Skill      `firestore:"skills" json:"skills"`                     // ✅ NUEVO
 
+	// ===== IDIOMAS Y COMPETENCIAS =====
+	Languages           []string `firestore:"languages" json:"languages"`
+	ToolProficiencies   []string `firestore:"toolProficiencies" json:"toolProficiencies"`
+	WeaponProficiencies []string `firestore:"weaponProficiencies" json:"weaponProficiencies"` // "simple", "martial" o nombres de armas
+	ArmorProficiencies  []string `firestore:"armorProficiencies" json:"armorProficiencies"`   // "light", "medium", "heavy", "shield"
+
+	// ===== DOTES Y RASGOS =====
+	Feats    []Feat    `firestore:"feats" json:"feats"`
+	Features []Feature `firestore:"features" json:"features"`
+
+	// ===== RECURSOS Y DADOS DE GOLPE =====
+	Resources []Resource    `firestore:"resources" json:"resources"`
+	HitDice   []HitDicePool `firestore:"hitDice" json:"hitDice"`
+
+	// ===== PROGRESIÓN =====
+	Experience     int  `firestore:"experience" json:"experience"`
+	ReadyToLevelUp bool `firestore:"readyToLevelUp" json:"readyToLevelUp"` // Calculado con la tabla de XP o por hito
+
 	// ===== METADATA =====
 	CreatedAt time.Time `firestore:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time `firestore:"updatedAt" json:"updatedAt"`
@@ -153,6 +245,252 @@ type CreateCharacterRequest struct {
 	// ✅ NUEVO: Proficiencies
 	SavingThrows SavingThrows `json:"savingThrows"`
 	Skills       []Skill      `json:"skills"`
+
+	// Origen
+	Race       string      `json:"race" binding:"max=50"`
+	Subrace    string      `json:"subrace" binding:"max=50"`
+	Background *Background `json:"background,omitempty"`
+	Alignment  string      `json:"alignment" binding:"omitempty,oneof=LG NG CG LN N CN LE NE CE unaligned"`
+
+	// Idiomas y competencias
+	Languages           []string `json:"languages" binding:"max=20,dive,required,max=30"`
+	ToolProficiencies   []string `json:"toolProficiencies" binding:"max=20,dive,required,max=50"`
+	WeaponProficiencies []string `json:"weaponProficiencies" binding:"max=50,dive,required,max=50"`
+	ArmorProficiencies  []string `json:"armorProficiencies" binding:"max=4,dive,required,oneof=light medium heavy shield"`
+
+	// Dotes y rasgos
+	Feats    []Feat    `json:"feats" binding:"max=30,dive"`
+	Features []Feature `json:"features" binding:"max=100,dive"`
+
+	// Recursos y dados de golpe (si no se envían dados, se infieren de la clase)
+	Resources []Resource    `json:"resources" binding:"max=30,dive"`
+	HitDice   []HitDicePool `json:"hitDice" binding:"max=4,dive"`
+}
+
+// UpdateCharacterRequest - edición de un personaje. Origen, competencias, dotes, rasgos,
+// recursos y dados de golpe son opcionales: si no se envían se conservan los actuales.
+type UpdateCharacterRequest struct {
+	// Básico
+	Name  string `json:"name" binding:"required,min=2,max=50"`
+	Class string `json:"class" binding:"required,min=2,max=50"`
+	Level int    `json:"level" binding:"required,min=1,max=20"`
+
+	// Combat
+	MaxHP      int `json:"maxHp" binding:"required,min=1,max=999"`
+	ArmorClass int `json:"armorClass" binding:"required,min=1,max=30"`
+	Initiative int `json:"initiative" binding:"min=-5,max=15"`
+	Speed      int `json:"speed" binding:"required,min=0,max=120"`
+
+	AbilityScores AbilityScores `json:"abilityScores" binding:"required"`
+	SavingThrows  SavingThrows  `json:"savingThrows"`
+	Skills        []Skill       `json:"skills"`
+
+	// Origen
+	Race       *string     `json:"race" binding:"omitempty,max=50"`
+	Subrace    *string     `json:"subrace" binding:"omitempty,max=50"`
+	Background *Background `json:"background,omitempty"`
+	Alignment  *string     `json:"alignment" binding:"omitempty,oneof=LG NG CG LN N CN LE NE CE unaligned"`
+
+	// Idiomas y competencias
+	Languages           []string `json:"languages" binding:"max=20,dive,required,max=30"`
+	ToolProficiencies   []string `json:"toolProficiencies" binding:"max=20,dive,required,max=50"`
+	WeaponProficiencies []string `json:"weaponProficiencies" binding:"max=50,dive,required,max=50"`
+	ArmorProficiencies  []string `json:"armorProficiencies" binding:"max=4,dive,required,oneof=light medium heavy shield"`
+
+	// Dotes y rasgos
+	Feats    []Feat    `json:"feats" binding:"max=30,dive"`
+	Features []Feature `json:"features" binding:"max=100,dive"`
+
+	// Recursos y dados de golpe
+	Resources []Resource    `json:"resources" binding:"max=30,dive"`
+	HitDice   []HitDicePool `json:"hitDice" binding:"max=4,dive"`
+}
+
+type AttachCharacterRequest struct {
+	CampaignID string `json:"campaignId" binding:"required"`
+}
+
+// ImportReport resume el resultado de importar un personaje desde otra herramienta
+type ImportReport struct {
+	Format        string   `json:"format"` // "dndbeyond", "foundry" o "dm-events"
+	ImportedItems int      `json:"importedItems"`
+	Unmapped      []string `json:"unmapped"` // Campos que no se pudieron mapear
+	Warnings      []string `json:"warnings"`
+}
+
+// DerivedStats son los valores calculados a partir de la hoja del personaje
+type DerivedStats struct {
+	AbilityModifiers  map[string]int `json:"abilityModifiers"`
+	SavingThrows      map[string]int `json:"savingThrows"`
+	Skills            map[string]int `json:"skills"`
+	PassivePerception int            `json:"passivePerception"`
+	InventoryValue    float64        `json:"inventoryValue"` // En po, incluye monedas
+	NetWorth          Worth          `json:"netWorth"`       // Items + monedas en pc
+	Speed             int            `json:"speed"`          // Con penalizaciones de carga y armadura
+	Encumbrance       Encumbrance    `json:"encumbrance"`
+}
+
+// CharacterExport es el bundle JSON de exportación, re-importable en otra campaña
+type CharacterExport struct {
+	Format     string          `json:"format"` // Siempre "dm-events"
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Character  Character       `json:"character"`
+	Derived    DerivedStats    `json:"derived"`
+	Inventory  []InventoryItem `json:"inventory"`
+	Currency   Currency        `json:"currency"`
+}
+
+type RestRequest struct {
+	Type string `json:"type" binding:"required,oneof=short long"`
+}
+
+type UseFeatureRequest struct {
+	Amount int `json:"amount" binding:"min=0,max=99"` // 0 = 1 uso
+}
+
+type ResourceAmountRequest struct {
+	Amount int `json:"amount" binding:"min=0,max=999"` // Gastar: 0 = 1. Restaurar: 0 = al máximo
+}
+
+// ItemChargesRequest - gastar: amount (0 = 1) o el costo del conjuro indicado.
+// Recargar: amount 0 tira la fórmula de recarga (o recarga todo si no tiene).
+type ItemChargesRequest struct {
+	Amount int    `json:"amount" binding:"min=0,max=100"`
+	Spell  string `json:"spell,omitempty" binding:"max=100"`
+}
+
+// ItemChargesResponse - estado de las cargas tras gastar o recargar
+type ItemChargesResponse struct {
+	Item    InventoryItem `json:"item"`
+	Changed int           `json:"changed"`        // Cargas gastadas o recuperadas
+	Roll    *DiceRoll     `json:"roll,omitempty"` // Tirada de recarga
+}
+
+type SpendHitDiceRequest struct {
+	Die   int `json:"die" binding:"required,oneof=6 8 10 12"`
+	Count int `json:"count" binding:"required,min=1,max=20"`
+}
+
+// ===========================
+// PROGRESIÓN (XP / HITOS)
+// ===========================
+
+const (
+	ProgressionXP        = "xp"
+	ProgressionMilestone = "milestone"
+)
+
+// XPAward representa una entrada del historial de XP de un personaje
+type XPAward struct {
+	ID           string    `firestore:"id" json:"id"`
+	CharacterID  string    `firestore:"characterId" json:"characterId"`
+	CampaignID   string    `firestore:"campaignId" json:"campaignId"`
+	Type         string    `firestore:"type" json:"type"` // "xp" o "milestone"
+	Amount       int       `firestore:"amount" json:"amount"`
+	Reason       string    `firestore:"reason" json:"reason"`
+	AwardedBy    string    `firestore:"awardedBy" json:"awardedBy"`
+	TotalAfter   int       `firestore:"totalAfter" json:"totalAfter"`
+	LevelAtAward int       `firestore:"levelAtAward" json:"levelAtAward"`
+	CreatedAt    time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+type UpdateProgressionRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=xp milestone"`
+}
+
+type AwardXPRequest struct {
+	CharacterIDs []string `json:"characterIds" binding:"required,min=1,max=20,dive,required"`
+	Amount       int      `json:"amount" binding:"required,min=1,max=1000000"`
+	Split        bool     `json:"split"` // Repartir el total entre los personajes
+	Reason       string   `json:"reason" binding:"max=200"`
+}
+
+type AwardMilestoneRequest struct {
+	CharacterIDs []string `json:"characterIds" binding:"required,min=1,max=20,dive,required"`
+	Reason       string   `json:"reason" binding:"max=200"`
+}
+
+// ===========================
+// HISTORIAL DE PERSONAJES
+// ===========================
+
+const (
+	CharacterVersionUpdate    = "update"
+	CharacterVersionRestore   = "restore"
+	CharacterVersionRest      = "rest"
+	CharacterVersionXP        = "xp"
+	CharacterVersionMilestone = "milestone"
+	CharacterVersionImport    = "import"    // Estado inicial: Snapshot es el personaje importado
+	CharacterVersionEquipment = "equipment" // CA recalculada al cambiar el equipo
+)
+
+// FieldChange representa el cambio de un campo de la hoja
+type FieldChange struct {
+	Field  string      `firestore:"field" json:"field"`
+	Before interface{} `firestore:"before" json:"before"`
+	After  interface{} `firestore:"after" json:"after"`
+}
+
+// CharacterVersion guarda el estado del personaje ANTES de una edición
+// junto con quién la hizo y qué cambió. Restaurarla reescribe ese estado.
+type CharacterVersion struct {
+	ID           string        `firestore:"id" json:"id"`
+	CharacterID  string        `firestore:"characterId" json:"characterId"`
+	CampaignID   string        `firestore:"campaignId" json:"campaignId"`
+	Action       string        `firestore:"action" json:"action"` // CharacterVersion*
+	EditedBy     string        `firestore:"editedBy" json:"editedBy"`
+	RestoredFrom string        `firestore:"restoredFrom,omitempty" json:"restoredFrom,omitempty"` // ID de la versión restaurada
+	Changes      []FieldChange `firestore:"changes" json:"changes"`
+	Snapshot     Character     `firestore:"snapshot" json:"snapshot"`
+	CreatedAt    time.Time     `firestore:"createdAt" json:"createdAt"`
+}
+
+// ===========================
+// ATAQUES
+// ===========================
+
+// DiceRoll representa el resultado de tirar una expresión de dados
+type DiceRoll struct {
+	Expression string `json:"expression"`
+	Rolls      []int  `json:"rolls"`
+	Modifier   int    `json:"modifier"`
+	Total      int    `json:"total"`
+}
+
+// Attack es un ataque calculado a partir de un arma equipada
+type Attack struct {
+	ItemID          string       `json:"itemId,omitempty"` // Vacío para el ataque desarmado
+	Name            string       `json:"name"`
+	Ability         string       `json:"ability"` // "strength" o "dexterity"
+	Proficient      bool         `json:"proficient"`
+	AttackBonus     int          `json:"attackBonus"`
+	Damage          string       `json:"damage"` // Ej: "1d8+3"
+	DamageType      string       `json:"damageType,omitempty"`
+	VersatileDamage string       `json:"versatileDamage,omitempty"`
+	Reach           int          `json:"reach,omitempty"` // En pies, ataques cuerpo a cuerpo
+	Range           *WeaponRange `json:"range,omitempty"` // Armas a distancia o arrojadizas
+	Properties      []string     `json:"properties"`
+}
+
+type AttackRollRequest struct {
+	TargetCombatantID string `json:"targetCombatantId" binding:"required"`
+	Mode              string `json:"mode" binding:"omitempty,oneof=normal advantage disadvantage"`
+	Versatile         bool   `json:"versatile"` // Usar el daño a dos manos
+}
+
+// AttackRollResult es el resultado de tirar un ataque contra un combatiente
+type AttackRollResult struct {
+	Attack     Attack    `json:"attack"`
+	TargetID   string    `json:"targetId"`
+	TargetName string    `json:"targetName"`
+	TargetAC   int       `json:"targetAc"`
+	D20        []int     `json:"d20"`
+	Natural    int       `json:"natural"`
+	Total      int       `json:"total"`
+	Hit        bool      `json:"hit"`
+	Critical   bool      `json:"critical"`
+	DamageRoll *DiceRoll `json:"damageRoll,omitempty"`
 }
 
 // ===========================
@@ -227,28 +565,103 @@ type Note struct {
 	AuthorID   string    `firestore:"authorId" json:"authorId"`
 	AuthorName string    `firestore:"authorName" json:"authorName"`
 	Title      string    `firestore:"title" json:"title"`
-	Content    string    `firestore:"content" json:"content"`
-	IsShared   bool      `firestore:"isShared" json:"isShared"`
+	Content    string    `firestore:"content" json:"content"`   // Markdown con enlaces [[...]]
+	IsShared   bool      `firestore:"isShared" json:"isShared"` // true si la audiencia es "players"
 	Category   string    `firestore:"category" json:"category"`
 	Tags       []string  `firestore:"tags" json:"tags"`
 	CreatedAt  time.Time `firestore:"createdAt" json:"createdAt"`
 	UpdatedAt  time.Time `firestore:"updatedAt" json:"updatedAt"`
+
+	// Audiencia: private (solo el autor; para el DM, "solo DM"), players (toda la mesa)
+	// o specific (el autor y los jugadores de SharedWith). Vacío en notas anteriores:
+	// se deriva de IsShared.
+	Audience   string   `firestore:"audience" json:"audience"`
+	SharedWith []string `firestore:"sharedWith" json:"sharedWith"`
+
+	// Enlaces resueltos al guardar; LinkTargets ("note:<id>", "character:<id>") es el
+	// índice de backlinks (consultas array-contains)
+	Links       []NoteLink `firestore:"links" json:"links"`
+	LinkTargets []string   `firestore:"linkTargets" json:"-"`
+}
+
+// Tipos de destino de un enlace [[tipo:Nombre]]. NPCs y lugares son notas de esa categoría.
+const (
+	LinkKindNote      = "note"
+	LinkKindCharacter = "character"
+	LinkKindNPC       = "npc"
+	LinkKindLocation  = "location"
+)
+
+// NoteLink - enlace [[...]] del contenido resuelto a una entidad de la campaña
+type NoteLink struct {
+	Raw      string `firestore:"raw" json:"raw"`           // Texto entre [[ ]]
+	Kind     string `firestore:"kind" json:"kind"`         // note, character, npc, location
+	TargetID string `firestore:"targetId" json:"targetId"` // ID de la nota o personaje
+	Title    string `firestore:"title" json:"title"`       // Nombre actual del destino
+	Label    string `firestore:"label" json:"label"`       // Texto a mostrar ([[destino|texto]])
+	Secret   bool   `firestore:"secret" json:"secret"`     // Solo aparece en secciones secretas
+}
+
+// Audiencias de una nota
+const (
+	NoteAudiencePrivate  = "private"
+	NoteAudiencePlayers  = "players"
+	NoteAudienceSpecific = "specific"
+)
+
+// NoteBacklink - nota visible que enlaza a una nota o personaje
+type NoteBacklink struct {
+	NoteID     string    `json:"noteId"`
+	Title      string    `json:"title"`
+	Category   string    `json:"category"`
+	AuthorName string    `json:"authorName"`
+	IsShared   bool      `json:"isShared"`
+	Labels     []string  `json:"labels"` // Textos de los enlaces que apuntan al destino
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// NoteSearchHit - nota encontrada con título y fragmento resaltados con <mark>
+type NoteSearchHit struct {
+	Note    Note    `json:"note"`
+	Score   float64 `json:"score"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+}
+
+type NoteSearchResponse struct {
+	Hits   []NoteSearchHit `json:"hits"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
 }
 
 type CreateNoteRequest struct {
 	Title    string   `json:"title" binding:"required,min=1,max=200"`
-	Content  string   `json:"content" binding:"max=10000"`
+	Content  string   `json:"content" binding:"max=50000"`
 	IsShared bool     `json:"isShared"`
 	Category string   `json:"category" binding:"required,oneof=session npc location plot other"`
 	Tags     []string `json:"tags" binding:"max=10,dive,max=30"`
+
+	// Audience tiene prioridad sobre IsShared; SharedWith solo aplica a "specific"
+	Audience   string   `json:"audience" binding:"omitempty,oneof=private players specific"`
+	SharedWith []string `json:"sharedWith" binding:"max=20,dive,required"`
 }
 
 type UpdateNoteRequest struct {
 	Title    string   `json:"title" binding:"required,min=1,max=200"`
-	Content  string   `json:"content" binding:"max=10000"`
+	Content  string   `json:"content" binding:"max=50000"`
 	IsShared bool     `json:"isShared"`
 	Category string   `json:"category" binding:"required,oneof=session npc location plot other"`
 	Tags     []string `json:"tags" binding:"max=10,dive,max=30"`
+
+	// Audience tiene prioridad sobre IsShared; SharedWith solo aplica a "specific"
+	Audience   string   `json:"audience" binding:"omitempty,oneof=private players specific"`
+	SharedWith []string `json:"sharedWith" binding:"max=20,dive,required"`
+}
+
+// RevealNoteRequest - jugadores a los que el DM revela una nota durante la sesión
+type RevealNoteRequest struct {
+	UserIDs []string `json:"userIds" binding:"required,min=1,max=20,dive,required"`
 }
 
 // ===========================
@@ -265,6 +678,7 @@ const (
 	ItemTypeTool       ItemType = "tool"
 	ItemTypeConsumable ItemType = "consumable"
 	ItemTypeTreasure   ItemType = "treasure"
+	ItemTypeContainer  ItemType = "container"
 	ItemTypeOther      ItemType = "other"
 )
 
@@ -299,13 +713,31 @@ type InventoryItem struct {
 	Description string   `firestore:"description,omitempty" json:"description,omitempty"`
 
 	// Económico
-	Quantity int     `firestore:"quantity" json:"quantity"`
-	Value    float64 `firestore:"value" json:"value"`
+	Quantity     int     `firestore:"quantity" json:"quantity"`
+	PriceCP      int64   `firestore:"priceCp" json:"priceCp"`                               // Precio por unidad en pc
+	Denomination string  `firestore:"denomination,omitempty" json:"denomination,omitempty"` // Moneda en que se cotiza (cp, sp, ep, gp, pp)
+	Value        float64 `firestore:"value" json:"value"`                                   // Precio por unidad en po (derivado de PriceCP)
+	Weight       float64 `firestore:"weight" json:"weight"`                                 // Libras por unidad
+	Volume       float64 `firestore:"volume,omitempty" json:"volume,omitempty"`             // Pies cúbicos por unidad
+
+	// Estado
+	Equipped bool `firestore:"equipped" json:"equipped"`
+	Attuned  bool `firestore:"attuned" json:"attuned"`
+
+	// Contenedores: ContainerID es el item que lo contiene ("" = suelto)
+	ContainerID   string         `firestore:"containerId,omitempty" json:"containerId,omitempty"`
+	ContainerData *ContainerData `firestore:"containerData,omitempty" json:"containerData,omitempty"`
 
 	// Datos específicos por tipo (almacenados como JSON)
 	WeaponData *WeaponData `firestore:"weaponData,omitempty" json:"weaponData,omitempty"`
 	ArmorData  *ArmorData  `firestore:"armorData,omitempty" json:"armorData,omitempty"`
 
+	// Objeto mágico (cualquier tipo puede serlo)
+	MagicData *MagicItemData `firestore:"magicData,omitempty" json:"magicData,omitempty"`
+
+	// Efecto al usar un consumible
+	Effect *ConsumableEffect `firestore:"effect,omitempty" json:"effect,omitempty"`
+
 	// Open5e reference
 	Open5eSlug string `firestore:"open5eSlug,omitempty" json:"open5eSlug,omitempty"`
 
@@ -331,50 +763,552 @@ type ArmorData struct {
 	MagicBonus          int    `firestore:"magicBonus,omitempty" json:"magicBonus,omitempty"`
 }
 
+// ContainerData - capacidad de un contenedor (0 = sin límite)
+type ContainerData struct {
+	CapacityWeight       float64 `firestore:"capacityWeight,omitempty" json:"capacityWeight,omitempty"`             // Libras
+	CapacityVolume       float64 `firestore:"capacityVolume,omitempty" json:"capacityVolume,omitempty"`             // Pies cúbicos
+	IgnoreContentsWeight bool    `firestore:"ignoreContentsWeight,omitempty" json:"ignoreContentsWeight,omitempty"` // Bolsa de contención
+	NotCarried           bool    `firestore:"notCarried,omitempty" json:"notCarried,omitempty"`                     // Montura o carro: no cuenta para la carga
+}
+
+// ConsumableEffect - lo que hace un consumible al usarse (poción, comida mágica...)
+type ConsumableEffect struct {
+	HealDice         string   `firestore:"healDice,omitempty" json:"healDice,omitempty"`               // "2d4+2"
+	TemporaryHPDice  string   `firestore:"temporaryHpDice,omitempty" json:"temporaryHpDice,omitempty"` // "1d4+4" o fijo "5"
+	RemoveConditions []string `firestore:"removeConditions,omitempty" json:"removeConditions,omitempty"`
+}
+
+// Rarezas de objetos mágicos
+const (
+	RarityCommon    = "common"
+	RarityUncommon  = "uncommon"
+	RarityRare      = "rare"
+	RarityVeryRare  = "very rare"
+	RarityLegendary = "legendary"
+	RarityArtifact  = "artifact"
+)
+
+// MagicItemData - rareza, sintonía, cargas y conjuros de un objeto mágico
+type MagicItemData struct {
+	Rarity             string       `firestore:"rarity" json:"rarity"`
+	RequiresAttunement bool         `firestore:"requiresAttunement" json:"requiresAttunement"`
+	AttunementBy       []string     `firestore:"attunementBy,omitempty" json:"attunementBy,omitempty"` // Clases o razas que pueden sintonizarlo ([] = cualquiera)
+	Charges            *ItemCharges `firestore:"charges,omitempty" json:"charges,omitempty"`
+	Spells             []ItemSpell  `firestore:"spells,omitempty" json:"spells,omitempty"`
+}
+
+// ItemCharges - cargas del objeto. Recharge: "dawn", "short", "long" o "none";
+// RechargeFormula es lo que recupera ("1d6+1"); vacío = todas.
+type ItemCharges struct {
+	Current         int    `firestore:"current" json:"current"`
+	Max             int    `firestore:"max" json:"max"`
+	Recharge        string `firestore:"recharge" json:"recharge"`
+	RechargeFormula string `firestore:"rechargeFormula,omitempty" json:"rechargeFormula,omitempty"`
+}
+
+// ItemSpell - conjuro lanzable desde el objeto
+type ItemSpell struct {
+	Name       string `firestore:"name" json:"name"`
+	Level      int    `firestore:"level" json:"level"`
+	ChargeCost int    `firestore:"chargeCost" json:"chargeCost"` // 0 = no gasta cargas
+	SaveDC     int    `firestore:"saveDc,omitempty" json:"saveDc,omitempty"`
+}
+
 // Currency representa la moneda del personaje
 type Currency struct {
 	Copper   int `firestore:"copper" json:"copper"`
 	Silver   int `firestore:"silver" json:"silver"`
+	Electrum int `firestore:"electrum" json:"electrum"`
 	Gold     int `firestore:"gold" json:"gold"`
 	Platinum int `firestore:"platinum" json:"platinum"`
 }
 
+// ItemPrice - precio expresado en una moneda ("5 sp"); se guarda como pc
+type ItemPrice struct {
+	Amount       int64  `json:"amount" binding:"min=0,max=99999999"`
+	Denomination string `json:"denomination" binding:"required,oneof=cp sp ep gp pp"`
+}
+
+// Worth - valor total en pc con su desglose normalizado en po, pp y pc
+type Worth struct {
+	TotalCP   int64    `json:"totalCp"`
+	Breakdown Currency `json:"breakdown"`
+}
+
 // ===========================
 // REQUESTS
 // ===========================
 
+// CreateItemRequest - con open5eSlug de un item del compendio basta el slug:
+// nombre, tipo y datos se completan desde el catálogo. Quantity 0 = 1.
 type CreateItemRequest struct {
-	Name        string  `json:"name" binding:"required,min=1,max=100"`
-	Type        string  `json:"type" binding:"required"`
-	Description string  `json:"description" binding:"max=1000"`
-	Quantity    int     `json:"quantity" binding:"required,min=1,max=999"`
-	Value       float64 `json:"value" binding:"min=0,max=999999"`
+	Name        string     `json:"name" binding:"required_without=Open5eSlug,max=100"`
+	Type        string     `json:"type" binding:"required_without=Open5eSlug"`
+	Description string     `json:"description" binding:"max=1000"`
+	Quantity    int        `json:"quantity" binding:"min=0,max=999"`
+	Value       float64    `json:"value" binding:"min=0,max=999999"` // En po; ignorado si viene price
+	Price       *ItemPrice `json:"price,omitempty"`
+	Weight      float64    `json:"weight" binding:"min=0,max=9999"`
+	Volume      float64    `json:"volume" binding:"min=0,max=9999"`
+	ContainerID string     `json:"containerId,omitempty"`
 
 	// Datos opcionales
-	WeaponData *WeaponData `json:"weaponData,omitempty"`
-	ArmorData  *ArmorData  `json:"armorData,omitempty"`
+	WeaponData    *WeaponData       `json:"weaponData,omitempty"`
+	ArmorData     *ArmorData        `json:"armorData,omitempty"`
+	ContainerData *ContainerData    `json:"containerData,omitempty"`
+	MagicData     *MagicItemData    `json:"magicData,omitempty"`
+	Effect        *ConsumableEffect `json:"effect,omitempty"`
 
 	// Open5e reference
 	Open5eSlug string `json:"open5eSlug,omitempty"`
 }
 
+// BulkItemsRequest - crear y eliminar varios items en un solo batch
+type BulkItemsRequest struct {
+	Create []CreateItemRequest `json:"create" binding:"max=200,dive"`
+	Delete []string            `json:"delete" binding:"max=200,dive,required"`
+}
+
+// BulkItemsResponse - items creados (o pilas incrementadas) e IDs eliminados
+type BulkItemsResponse struct {
+	Items     []InventoryItem `json:"items"`
+	Deleted   []string        `json:"deleted"`
+	ItemCount int             `json:"itemCount"` // Items del personaje tras la operación
+}
+
+// StartingPack - equipo inicial (de clase o paquete del SRD) armado con slugs del compendio
+type StartingPack struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	Class    string             `json:"class,omitempty"`
+	Includes []string           `json:"includes,omitempty"` // IDs de otros paquetes
+	Items    []StartingPackItem `json:"items"`
+}
+
+type StartingPackItem struct {
+	Slug     string `json:"slug"`
+	Quantity int    `json:"quantity"`
+}
+
+// ApplyStartingPackRequest - packId vacío = el paquete de la clase del personaje
+type ApplyStartingPackRequest struct {
+	PackID string `json:"packId" binding:"max=50"`
+}
+
+// EquipmentResponse - resultado de equipar/sintonizar: item, CA derivada y avisos
+type EquipmentResponse struct {
+	Item       InventoryItem `json:"item"`
+	ArmorClass int           `json:"armorClass"`
+	Warnings   []string      `json:"warnings"`
+}
+
+// EquipmentSummary - equipo actual del personaje
+type EquipmentSummary struct {
+	ArmorClass int             `json:"armorClass"`
+	Equipped   []InventoryItem `json:"equipped"`
+	Attuned    []InventoryItem `json:"attuned"`
+	Warnings   []string        `json:"warnings"`
+}
+
+// UpdateItemRequest - edición parcial (PATCH); solo se aplican los campos enviados.
+// Cambiar el tipo descarta los datos de arma/armadura/contenedor/efecto que ya no corresponden.
 type UpdateItemRequest struct {
-	Quantity *int `json:"quantity,omitempty"`
+	Name          *string           `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Type          *string           `json:"type,omitempty"`
+	Description   *string           `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Quantity      *int              `json:"quantity,omitempty" binding:"omitempty,max=999"`       // <= 0 elimina el item
+	Value         *float64          `json:"value,omitempty" binding:"omitempty,min=0,max=999999"` // En po
+	Price         *ItemPrice        `json:"price,omitempty"`
+	Weight        *float64          `json:"weight,omitempty" binding:"omitempty,min=0,max=9999"`
+	Volume        *float64          `json:"volume,omitempty" binding:"omitempty,min=0,max=9999"`
+	WeaponData    *WeaponData       `json:"weaponData,omitempty"`
+	ArmorData     *ArmorData        `json:"armorData,omitempty"`
+	ContainerData *ContainerData    `json:"containerData,omitempty"`
+	MagicData     *MagicItemData    `json:"magicData,omitempty"`
+	Effect        *ConsumableEffect `json:"effect,omitempty"`
+	Open5eSlug    *string           `json:"open5eSlug,omitempty"`
 }
 
 type UpdateCurrencyRequest struct {
-	Copper   *int `json:"copper,omitempty"`
-	Silver   *int `json:"silver,omitempty"`
-	Gold     *int `json:"gold,omitempty"`
-	Platinum *int `json:"platinum,omitempty"`
+	Copper   *int    `json:"copper,omitempty"`
+	Silver   *int    `json:"silver,omitempty"`
+	Electrum *int    `json:"electrum,omitempty"`
+	Gold     *int    `json:"gold,omitempty"`
+	Platinum *int    `json:"platinum,omitempty"`
+	Reason   *string `json:"reason,omitempty"`
+}
+
+// CurrencyTransactionRequest - movimiento relativo: "+5 gp", "-120 sp".
+// Los pagos hacen cambio automáticamente con las demás monedas.
+type CurrencyTransactionRequest struct {
+	Amount string `json:"amount" binding:"required,max=30"`
+	Reason string `json:"reason" binding:"required,min=1,max=200"`
+}
+
+// UseItemResponse - resultado de usar un consumible. Item es nil si se agotó.
+type UseItemResponse struct {
+	Item              *InventoryItem  `json:"item"`
+	Heal              *DiceRoll       `json:"heal,omitempty"`
+	TemporaryHP       *DiceRoll       `json:"temporaryHp,omitempty"`
+	Healed            int             `json:"healed"`
+	RemovedConditions []string        `json:"removedConditions"`
+	Character         CharacterVitals `json:"character"`
+	CombatantID       string          `json:"combatantId,omitempty"` // Combatiente activo actualizado
+}
+
+// CharacterVitals - PG y condiciones tras aplicar un efecto
+type CharacterVitals struct {
+	ID          string   `json:"id"`
+	CurrentHP   int      `json:"currentHp"`
+	MaxHP       int      `json:"maxHp"`
+	TemporaryHP int      `json:"temporaryHp"`
+	Conditions  []string `json:"conditions"`
+}
+
+// MoveItemRequest - containerId "" saca el item del contenedor
+type MoveItemRequest struct {
+	ContainerID string `json:"containerId"`
+}
+
+type TransferItemRequest struct {
+	ToCharacterID string `json:"toCharacterId" binding:"required"`
+	Quantity      int    `json:"quantity" binding:"required,min=1,max=999"`
+}
+
+type TransferCurrencyRequest struct {
+	ToCharacterID string `json:"toCharacterId" binding:"required"`
+	Copper        int    `json:"copper" binding:"min=0"`
+	Silver        int    `json:"silver" binding:"min=0"`
+	Electrum      int    `json:"electrum" binding:"min=0"`
+	Gold          int    `json:"gold" binding:"min=0"`
+	Platinum      int    `json:"platinum" binding:"min=0"`
 }
 
 // ===========================
-// RESPONSE
+// TRANSFERENCIAS
 // ===========================
 
-type InventoryResponse struct {
+const (
+	TransferKindItem     = "item"
+	TransferKindCurrency = "currency"
+)
+
+// InventoryTransfer es la entrada de auditoría de un traspaso entre personajes
+type InventoryTransfer struct {
+	ID              string    `firestore:"id" json:"id"`
+	CampaignID      string    `firestore:"campaignId" json:"campaignId"`
+	Kind            string    `firestore:"kind" json:"kind"`                       // "item" o "currency"
+	FromCharacterID string    `firestore:"fromCharacterId" json:"fromCharacterId"` // "" = fondo del grupo
+	ToCharacterID   string    `firestore:"toCharacterId" json:"toCharacterId"`     // "" = fondo del grupo
+	PerformedBy     string    `firestore:"performedBy" json:"performedBy"`
+	ItemName        string    `firestore:"itemName,omitempty" json:"itemName,omitempty"`
+	Quantity        int       `firestore:"quantity,omitempty" json:"quantity,omitempty"`
+	Currency        *Currency `firestore:"currency,omitempty" json:"currency,omitempty"`
+	CreatedAt       time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+// ===========================
+// LIBRO DE MONEDAS
+// ===========================
+
+// CurrencyLedgerEntry registra cada movimiento de monedas de un personaje
+type CurrencyLedgerEntry struct {
+	ID          string    `firestore:"id" json:"id"`
+	CharacterID string    `firestore:"characterId" json:"characterId"`
+	CampaignID  string    `firestore:"campaignId" json:"campaignId"`
+	PerformedBy string    `firestore:"performedBy" json:"performedBy"`
+	Reason      string    `firestore:"reason" json:"reason"`
+	Amount      string    `firestore:"amount,omitempty" json:"amount,omitempty"` // Expresión original ("-120 sp")
+	Delta       Currency  `firestore:"delta" json:"delta"`                       // Cambio neto por moneda (con signo)
+	Balance     Currency  `firestore:"balance" json:"balance"`                   // Saldo resultante
+	CreatedAt   time.Time `firestore:"createdAt" json:"createdAt"`
+}
+
+// ===========================
+// TIENDAS
+// ===========================
+
+// Merchant es una tienda de la campaña gestionada por el DM.
+// El precio de venta es Price x Markup; al comprar a los jugadores paga Value x SellBackRate.
+type Merchant struct {
+	ID           string              `firestore:"id" json:"id"`
+	CampaignID   string              `firestore:"campaignId" json:"campaignId"`
+	Name         string              `firestore:"name" json:"name"`
+	Description  string              `firestore:"description,omitempty" json:"description,omitempty"`
+	Markup       float64             `firestore:"markup" json:"markup"`             // 1.0 = precio de lista
+	SellBackRate float64             `firestore:"sellBackRate" json:"sellBackRate"` // 0.5 = mitad del valor
+	IsOpen       bool                `firestore:"isOpen" json:"isOpen"`             // Visible para los jugadores
+	Stock        []MerchantStockItem `firestore:"stock" json:"stock"`
+	CreatedAt    time.Time           `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time           `firestore:"updatedAt" json:"updatedAt"`
+}
+
+type MerchantStockItem struct {
+	ID            string            `firestore:"id" json:"id"`
+	Name          string            `firestore:"name" json:"name"`
+	Type          ItemType          `firestore:"type" json:"type"`
+	Description   string            `firestore:"description,omitempty" json:"description,omitempty"`
+	PriceCP       int64             `firestore:"priceCp" json:"priceCp"`                               // Precio de lista por unidad en pc
+	Denomination  string            `firestore:"denomination,omitempty" json:"denomination,omitempty"` // Moneda en la que se muestra
+	Price         float64           `firestore:"price" json:"price"`                                   // En po, derivado de priceCp (el stock anterior solo tiene este)
+	Quantity      int               `firestore:"quantity" json:"quantity"`                             // Ignorado si Unlimited
+	Unlimited     bool              `firestore:"unlimited" json:"unlimited"`
+	Weight        float64           `firestore:"weight" json:"weight"`
+	Volume        float64           `firestore:"volume,omitempty" json:"volume,omitempty"`
+	WeaponData    *WeaponData       `firestore:"weaponData,omitempty" json:"weaponData,omitempty"`
+	ArmorData     *ArmorData        `firestore:"armorData,omitempty" json:"armorData,omitempty"`
+	ContainerData *ContainerData    `firestore:"containerData,omitempty" json:"containerData,omitempty"`
+	MagicData     *MagicItemData    `firestore:"magicData,omitempty" json:"magicData,omitempty"`
+	Effect        *ConsumableEffect `firestore:"effect,omitempty" json:"effect,omitempty"`
+	Open5eSlug    string            `firestore:"open5eSlug,omitempty" json:"open5eSlug,omitempty"`
+}
+
+// CreateMerchantRequest - markup por defecto 1.0 y sellBackRate 0.5
+type CreateMerchantRequest struct {
+	Name         string   `json:"name" binding:"required,min=1,max=100"`
+	Description  string   `json:"description" binding:"max=1000"`
+	Markup       *float64 `json:"markup" binding:"omitempty,min=0,max=10"`
+	SellBackRate *float64 `json:"sellBackRate" binding:"omitempty,min=0,max=1"`
+	IsOpen       bool     `json:"isOpen"`
+}
+
+type UpdateMerchantRequest struct {
+	Name         *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Description  *string  `json:"description" binding:"omitempty,max=1000"`
+	Markup       *float64 `json:"markup" binding:"omitempty,min=0,max=10"`
+	SellBackRate *float64 `json:"sellBackRate" binding:"omitempty,min=0,max=1"`
+	IsOpen       *bool    `json:"isOpen"`
+}
+
+// AddStockRequest - nombre y tipo son opcionales si viene open5eSlug (se completan con el compendio)
+type AddStockRequest struct {
+	Name          string            `json:"name" binding:"required_without=Open5eSlug,max=100"`
+	Type          string            `json:"type" binding:"required_without=Open5eSlug"`
+	Description   string            `json:"description" binding:"max=1000"`
+	Value         float64           `json:"value" binding:"min=0,max=999999"` // En po; ignorado si viene price
+	Price         *ItemPrice        `json:"price,omitempty"`
+	Quantity      int               `json:"quantity" binding:"min=0,max=9999"`
+	Unlimited     bool              `json:"unlimited"`
+	Weight        float64           `json:"weight" binding:"min=0,max=9999"`
+	Volume        float64           `json:"volume" binding:"min=0,max=9999"`
+	WeaponData    *WeaponData       `json:"weaponData,omitempty"`
+	ArmorData     *ArmorData        `json:"armorData,omitempty"`
+	ContainerData *ContainerData    `json:"containerData,omitempty"`
+	MagicData     *MagicItemData    `json:"magicData,omitempty"`
+	Effect        *ConsumableEffect `json:"effect,omitempty"`
+	Open5eSlug    string            `json:"open5eSlug,omitempty"`
+}
+
+// MerchantTradeRequest - compra (itemId = ID del stock) o venta (itemId = ID del inventario)
+type MerchantTradeRequest struct {
+	CharacterID string `json:"characterId" binding:"required"`
+	ItemID      string `json:"itemId" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1,max=999"`
+}
+
+type MerchantTradeResponse struct {
+	Item     InventoryItem `json:"item"`
+	Total    Worth         `json:"total"`    // Pagado (compra) o recibido (venta), en pc con desglose
+	Currency Currency      `json:"currency"` // Monedas del personaje tras la operación
+}
+
+// ===========================
+// FONDO DEL GRUPO
+// ===========================
+// Los items del grupo viven en party_items (characterId vacío) y las
+// monedas en party_currencies (doc ID = campaignId).
+
+type PartyStashResponse struct {
 	Items      []InventoryItem `json:"items"`
 	Currency   Currency        `json:"currency"`
-	TotalValue float64         `json:"totalValue"`
+	TotalValue float64         `json:"totalValue"` // En po, items + monedas (igual a netWorth)
+	ItemsWorth Worth           `json:"itemsWorth"`
+	CoinsWorth Worth           `json:"coinsWorth"`
+	NetWorth   Worth           `json:"netWorth"`
+}
+
+type PartyCurrencyRequest struct {
+	Copper   int `json:"copper" binding:"min=0,max=999999"`
+	Silver   int `json:"silver" binding:"min=0,max=999999"`
+	Electrum int `json:"electrum" binding:"min=0,max=999999"`
+	Gold     int `json:"gold" binding:"min=0,max=999999"`
+	Platinum int `json:"platinum" binding:"min=0,max=999999"`
+}
+
+// SplitCurrencyRequest - reparto del tesoro; sin characterIds se reparte entre todos los personajes
+type SplitCurrencyRequest struct {
+	CharacterIDs []string `json:"characterIds" binding:"max=50,dive,required"` // MAX_SPLIT_RECIPIENTS
+}
+
+type SplitCurrencyResponse struct {
+	Share      Currency `json:"share"`      // Lo que recibe cada personaje
+	Recipients []string `json:"recipients"` // IDs de personajes
+	Remainder  Currency `json:"remainder"`  // Lo que queda en el fondo
+}
+
+// ClaimPartyItemRequest - characterId es obligatorio para el DM; el jugador reclama para su personaje
+type ClaimPartyItemRequest struct {
+	CharacterID string `json:"characterId"`
+	Quantity    int    `json:"quantity" binding:"required,min=1,max=999"`
+}
+
+// ===========================
+// COMPENDIO Y HOMEBREW
+// ===========================
+
+// Orígenes de una entrada del compendio
+const (
+	SourceSRD      = "srd"
+	SourceHomebrew = "homebrew"
+)
+
+// Tipos de contenido homebrew
+const (
+	HomebrewKindItem    = "item"
+	HomebrewKindMonster = "monster"
+	HomebrewKindSpell   = "spell"
+)
+
+// CompendiumItem - item del compendio (SRD u homebrew). Los slugs son compatibles con Open5e.
+type CompendiumItem struct {
+	Slug          string            `firestore:"slug" json:"slug"`
+	Name          string            `firestore:"name" json:"name" binding:"required,min=1,max=100"`
+	Type          ItemType          `firestore:"type" json:"type" binding:"required"`
+	Category      string            `firestore:"category,omitempty" json:"category,omitempty" binding:"max=50"`
+	Cost          float64           `firestore:"cost" json:"cost" binding:"min=0,max=999999"`   // Precio en po
+	Weight        float64           `firestore:"weight" json:"weight" binding:"min=0,max=9999"` // Libras
+	Volume        float64           `firestore:"volume,omitempty" json:"volume,omitempty" binding:"min=0,max=9999"`
+	Description   string            `firestore:"description,omitempty" json:"description,omitempty" binding:"max=1000"`
+	WeaponData    *WeaponData       `firestore:"weaponData,omitempty" json:"weaponData,omitempty"`
+	ArmorData     *ArmorData        `firestore:"armorData,omitempty" json:"armorData,omitempty"`
+	ContainerData *ContainerData    `firestore:"containerData,omitempty" json:"containerData,omitempty"`
+	MagicData     *MagicItemData    `firestore:"magicData,omitempty" json:"magicData,omitempty"`
+	Effect        *ConsumableEffect `firestore:"effect,omitempty" json:"effect,omitempty"`
+	Source        string            `firestore:"-" json:"source,omitempty"`
+}
+
+// CompendiumMonster - criatura homebrew
+type CompendiumMonster struct {
+	Slug            string          `firestore:"slug" json:"slug"`
+	Name            string          `firestore:"name" json:"name" binding:"required,min=1,max=100"`
+	Size            string          `firestore:"size,omitempty" json:"size,omitempty" binding:"max=20"`
+	Type            string          `firestore:"type,omitempty" json:"type,omitempty" binding:"max=50"`
+	Alignment       string          `firestore:"alignment,omitempty" json:"alignment,omitempty" binding:"max=50"`
+	ArmorClass      int             `firestore:"armorClass" json:"armorClass" binding:"min=0,max=99"`
+	HitPoints       int             `firestore:"hitPoints" json:"hitPoints" binding:"min=1,max=9999"`
+	HitDice         string          `firestore:"hitDice,omitempty" json:"hitDice,omitempty" binding:"max=30"`
+	Speed           string          `firestore:"speed,omitempty" json:"speed,omitempty" binding:"max=100"`
+	ChallengeRating string          `firestore:"challengeRating,omitempty" json:"challengeRating,omitempty" binding:"max=10"`
+	AbilityScores   AbilityScores   `firestore:"abilityScores" json:"abilityScores"`
+	Description     string          `firestore:"description,omitempty" json:"description,omitempty" binding:"max=5000"`
+	Actions         []MonsterAction `firestore:"actions,omitempty" json:"actions,omitempty" binding:"max=30,dive"`
+	Source          string          `firestore:"-" json:"source,omitempty"`
+}
+
+type MonsterAction struct {
+	Name        string `firestore:"name" json:"name" binding:"required,min=1,max=100"`
+	Description string `firestore:"description,omitempty" json:"description,omitempty" binding:"max=2000"`
+	AttackBonus int    `firestore:"attackBonus,omitempty" json:"attackBonus,omitempty"`
+	DamageDice  string `firestore:"damageDice,omitempty" json:"damageDice,omitempty" binding:"max=30"`
+}
+
+// CompendiumSpell - conjuro homebrew
+type CompendiumSpell struct {
+	Slug          string   `firestore:"slug" json:"slug"`
+	Name          string   `firestore:"name" json:"name" binding:"required,min=1,max=100"`
+	Level         int      `firestore:"level" json:"level" binding:"min=0,max=9"`
+	School        string   `firestore:"school,omitempty" json:"school,omitempty" binding:"max=30"`
+	CastingTime   string   `firestore:"castingTime,omitempty" json:"castingTime,omitempty" binding:"max=50"`
+	Range         string   `firestore:"range,omitempty" json:"range,omitempty" binding:"max=50"`
+	Components    string   `firestore:"components,omitempty" json:"components,omitempty" binding:"max=200"`
+	Duration      string   `firestore:"duration,omitempty" json:"duration,omitempty" binding:"max=50"`
+	Concentration bool     `firestore:"concentration" json:"concentration"`
+	Ritual        bool     `firestore:"ritual" json:"ritual"`
+	Classes       []string `firestore:"classes,omitempty" json:"classes,omitempty" binding:"max=20"`
+	Description   string   `firestore:"description,omitempty" json:"description,omitempty" binding:"max=5000"`
+	Source        string   `firestore:"-" json:"source,omitempty"`
+}
+
+// HomebrewEntry - contenido propio de un DM. CampaignID "" = compartido en todas sus campañas.
+// Solo uno de Item, Monster o Spell está presente según Kind.
+type HomebrewEntry struct {
+	ID         string             `firestore:"id" json:"id"`
+	OwnerID    string             `firestore:"ownerId" json:"ownerId"`
+	CampaignID string             `firestore:"campaignId" json:"campaignId"`
+	Kind       string             `firestore:"kind" json:"kind"`
+	Slug       string             `firestore:"slug" json:"slug"`
+	Name       string             `firestore:"name" json:"name"`
+	Item       *CompendiumItem    `firestore:"item,omitempty" json:"item,omitempty"`
+	Monster    *CompendiumMonster `firestore:"monster,omitempty" json:"monster,omitempty"`
+	Spell      *CompendiumSpell   `firestore:"spell,omitempty" json:"spell,omitempty"`
+	CreatedAt  time.Time          `firestore:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time          `firestore:"updatedAt" json:"updatedAt"`
+}
+
+// HomebrewRequest - crear o editar una entrada; shared la comparte entre las campañas del DM
+type HomebrewRequest struct {
+	Kind    string             `json:"kind" binding:"required,oneof=item monster spell"`
+	Shared  bool               `json:"shared"`
+	Item    *CompendiumItem    `json:"item,omitempty"`
+	Monster *CompendiumMonster `json:"monster,omitempty"`
+	Spell   *CompendiumSpell   `json:"spell,omitempty"`
+}
+
+// HomebrewPack - paquete JSON para exportar/importar contenido homebrew. Las entradas se
+// validan una a una al importar, para informar las inválidas sin rechazar el paquete.
+type HomebrewPack struct {
+	Name       string              `json:"name" binding:"max=100"`
+	ExportedAt time.Time           `json:"exportedAt"`
+	Items      []CompendiumItem    `json:"items" binding:"max=1000"`
+	Monsters   []CompendiumMonster `json:"monsters" binding:"max=1000"`
+	Spells     []CompendiumSpell   `json:"spells" binding:"max=1000"`
 }
+
+// ImportPackResponse - entradas creadas y actualizadas (por slug) al importar
+type ImportPackResponse struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Errors  []string `json:"errors"`
+}
+
+// ===========================
+// RESPONSE
+// ===========================
+
+type InventoryResponse struct {
+	Items       []InventoryItem `json:"items"`
+	Currency    Currency        `json:"currency"`
+	TotalValue  float64         `json:"totalValue"`  // En po, items + monedas (igual a netWorth)
+	ItemsWorth  Worth           `json:"itemsWorth"`  // Valor de los items en pc
+	CoinsWorth  Worth           `json:"coinsWorth"`  // Valor de las monedas en pc
+	NetWorth    Worth           `json:"netWorth"`    // Items + monedas en pc
+	TotalWeight float64         `json:"totalWeight"` // Libras, items + monedas
+	Encumbrance Encumbrance     `json:"encumbrance"`
+	Tree        []InventoryNode `json:"tree"` // Items sueltos con sus contenidos anidados
+}
+
+// InventoryNode - item con los items que contiene
+type InventoryNode struct {
+	Item           InventoryItem   `json:"item"`
+	Contents       []InventoryNode `json:"contents"`
+	ContentsWeight float64         `json:"contentsWeight"`
+	ContentsVolume float64         `json:"contentsVolume"`
+}
+
+// Encumbrance - carga según la regla variante de estorbo (FUE x5 / x10 / x15)
+type Encumbrance struct {
+	CarriedWeight       float64 `json:"carriedWeight"`
+	CurrencyWeight      float64 `json:"currencyWeight"` // 50 monedas = 1 libra
+	CarryingCapacity    float64 `json:"carryingCapacity"`
+	EncumberedAt        float64 `json:"encumberedAt"`
+	HeavilyEncumberedAt float64 `json:"heavilyEncumberedAt"`
+	Status              string  `json:"status"` // unencumbered, encumbered, heavily_encumbered, over_capacity
+	SpeedPenalty        int     `json:"speedPenalty"`
+}
+
+const (
+	EncumbranceNone         = "unencumbered"
+	EncumbranceEncumbered   = "encumbered"
+	EncumbranceHeavy        = "heavily_encumbered"
+	EncumbranceOverCapacity = "over_capacity"
+)
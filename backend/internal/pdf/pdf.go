@@ -0,0 +1,177 @@
+// backend/internal/pdf/pdf.go
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ===========================
+// GENERADOR PDF MÍNIMO
+// ===========================
+// Escribe documentos PDF 1.4 con las fuentes estándar Helvetica y
+// Helvetica-Bold (sin incrustar), suficiente para hojas imprimibles.
+
+const (
+	PageWidth  = 612.0 // Carta, en puntos
+	PageHeight = 792.0
+)
+
+// Document representa un PDF en construcción
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+// New crea un documento vacío
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage agrega una página nueva y la deja como actual
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) current() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text escribe texto con su línea base en (x, y); y se mide desde arriba
+func (d *Document) Text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, size, x, PageHeight-y, escape(text))
+}
+
+// Line dibuja una línea entre dos puntos (y medida desde arriba)
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current(), "%.2f %.2f m %.2f %.2f l S\n",
+		x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Rect dibuja el borde de un rectángulo con esquina superior izquierda en (x, y)
+func (d *Document) Rect(x, y, w, h float64) {
+	fmt.Fprintf(d.current(), "%.2f %.2f %.2f %.2f re S\n",
+		x, PageHeight-y-h, w, h)
+}
+
+// TextWidth estima el ancho de un texto en Helvetica
+func TextWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("il.,:;'|!", r):
+			width += 0.25
+		case strings.ContainsRune("MWmw@", r):
+			width += 0.85
+		case r >= 'A' && r <= 'Z':
+			width += 0.67
+		default:
+			width += 0.53
+		}
+	}
+	return width * size
+}
+
+// Wrap divide un texto en líneas que no superen el ancho indicado
+func Wrap(text string, size, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && TextWidth(candidate, size) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Bytes serializa el documento completo
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	offsets := []int{}
+	writeObj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catálogo, 2: árbol de páginas, 3-4: fuentes, luego página + contenido por página
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 6+i*2))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// winAnsiExtras - caracteres fuera de Latin-1 con código propio en WinAnsiEncoding
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '•': 0x95, '–': 0x96, '—': 0x97,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '™': 0x99,
+}
+
+// escape convierte el texto a WinAnsi y escapa los caracteres especiales de PDF
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if code, ok := winAnsiExtras[r]; ok {
+				fmt.Fprintf(&b, "\\%03o", code)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
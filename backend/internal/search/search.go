@@ -0,0 +1,355 @@
+// backend/internal/search/search.go
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ===========================
+// ÍNDICE INVERTIDO EN MEMORIA
+// ===========================
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	snippetTokens = 30 // Palabras por fragmento
+	snippetBefore = 8  // Palabras antes del primer resultado
+)
+
+// Pesos por campo al puntuar
+const (
+	titleWeight   = 3.0
+	tagWeight     = 2.0
+	contentWeight = 1.0
+	prefixPenalty = 0.5 // Coincidencias por prefijo valen la mitad que las exactas
+)
+
+// stopWords - palabras demasiado comunes para indexar (español e inglés)
+var stopWords = map[string]bool{
+	"a": true, "al": true, "con": true, "de": true, "del": true, "el": true, "en": true, "es": true,
+	"la": true, "las": true, "lo": true, "los": true, "por": true, "que": true, "se": true, "su": true,
+	"un": true, "una": true, "y": true, "o": true, "the": true, "of": true, "and": true, "to": true,
+	"in": true, "is": true, "an": true,
+}
+
+// Document - texto indexable
+type Document struct {
+	ID        string
+	Title     string
+	Content   string
+	Category  string
+	Tags      []string
+	UpdatedAt time.Time
+}
+
+// Query - búsqueda; Text vacío solo filtra. Visible descarta documentos que el usuario no puede ver.
+type Query struct {
+	Text     string
+	Category string
+	Tags     []string // Todas deben estar presentes
+	Visible  func(id string) bool
+	Limit    int
+	Offset   int
+}
+
+// Hit - resultado con el título y un fragmento del contenido resaltados con <mark>
+type Hit struct {
+	ID      string
+	Score   float64
+	Title   string
+	Snippet string
+}
+
+// Result - página de resultados
+type Result struct {
+	Hits  []Hit
+	Total int
+}
+
+// Index - índice invertido de un conjunto de documentos. Es inmutable una vez
+// construido: ante cualquier cambio se arma uno nuevo.
+type Index struct {
+	docs     map[string]Document
+	postings map[string]map[string]float64 // término -> documento -> peso
+	terms    []string                      // Términos ordenados (búsqueda por prefijo)
+}
+
+// NewIndex indexa los documentos
+func NewIndex(docs []Document) *Index {
+	ix := &Index{
+		docs:     make(map[string]Document, len(docs)),
+		postings: make(map[string]map[string]float64),
+	}
+
+	for _, doc := range docs {
+		ix.docs[doc.ID] = doc
+		ix.add(doc.ID, doc.Title, titleWeight)
+		ix.add(doc.ID, strings.Join(doc.Tags, " "), tagWeight)
+		ix.add(doc.ID, doc.Content, contentWeight)
+	}
+
+	ix.terms = make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		ix.terms = append(ix.terms, term)
+	}
+	sort.Strings(ix.terms)
+	return ix
+}
+
+// Len - cantidad de documentos indexados
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+func (ix *Index) add(id, text string, weight float64) {
+	for _, tok := range tokenize(text) {
+		if stopWords[tok.term] {
+			continue
+		}
+		docs, ok := ix.postings[tok.term]
+		if !ok {
+			docs = make(map[string]float64)
+			ix.postings[tok.term] = docs
+		}
+		docs[id] += weight
+	}
+}
+
+// Search busca los documentos que contienen todos los términos (completos o como
+// prefijo), ordenados por relevancia; sin texto, por fecha de actualización.
+func (ix *Index) Search(q Query) Result {
+	queryTerms := []string{}
+	for _, tok := range tokenize(q.Text) {
+		if !stopWords[tok.term] {
+			queryTerms = append(queryTerms, tok.term)
+		}
+	}
+
+	scores := map[string]float64{}
+	matched := map[string]bool{} // Términos del índice que coincidieron (para resaltar)
+	if len(queryTerms) == 0 {
+		for id := range ix.docs {
+			scores[id] = 0
+		}
+	} else {
+		for i, term := range queryTerms {
+			termScores := ix.scoreTerm(term, matched)
+			if i == 0 {
+				scores = termScores
+				continue
+			}
+			for id := range scores {
+				if score, ok := termScores[id]; ok {
+					scores[id] += score
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+	}
+
+	hits := []Hit{}
+	for id, score := range scores {
+		doc := ix.docs[id]
+		if q.Category != "" && !strings.EqualFold(doc.Category, q.Category) {
+			continue
+		}
+		if !hasTags(doc.Tags, q.Tags) {
+			continue
+		}
+		if q.Visible != nil && !q.Visible(id) {
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		a, b := ix.docs[hits[i].ID], ix.docs[hits[j].ID]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	result := Result{Hits: []Hit{}, Total: len(hits)}
+	if offset >= len(hits) {
+		return result
+	}
+	end := offset + limit
+	if end > len(hits) {
+		end = len(hits)
+	}
+	for _, hit := range hits[offset:end] {
+		doc := ix.docs[hit.ID]
+		hit.Title = highlight(doc.Title, matched, 0)
+		hit.Snippet = highlight(doc.Content, matched, snippetTokens)
+		result.Hits = append(result.Hits, hit)
+	}
+	return result
+}
+
+// scoreTerm puntúa los documentos que contienen el término (tf·idf). También
+// cuentan los términos que empiezan por él, con menor peso.
+func (ix *Index) scoreTerm(term string, matched map[string]bool) map[string]float64 {
+	scores := map[string]float64{}
+	apply := func(indexTerm string, factor float64) {
+		docs := ix.postings[indexTerm]
+		idf := math.Log(1 + float64(len(ix.docs))/float64(len(docs)))
+		for id, weight := range docs {
+			if score := weight * idf * factor; score > scores[id] {
+				scores[id] = score
+			}
+		}
+		matched[indexTerm] = true
+	}
+
+	if _, ok := ix.postings[term]; ok {
+		apply(term, 1)
+	}
+	start := sort.SearchStrings(ix.terms, term)
+	for i := start; i < len(ix.terms) && strings.HasPrefix(ix.terms[i], term); i++ {
+		if ix.terms[i] != term {
+			apply(ix.terms[i], prefixPenalty)
+		}
+	}
+	return scores
+}
+
+// ===========================
+// TOKENIZACIÓN Y RESALTADO
+// ===========================
+
+type token struct {
+	term       string // Normalizado: minúsculas y sin tildes
+	start, end int    // Posición en el texto original (bytes)
+}
+
+// Normalize pasa a minúsculas y quita tildes ("Dragón" -> "dragon")
+func Normalize(text string) string {
+	// La cadena de transformaciones guarda estado: se arma una por llamada
+	foldAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(foldAccents, strings.ToLower(text))
+	if err != nil {
+		return strings.ToLower(text)
+	}
+	return folded
+}
+
+// tokenize divide el texto en palabras (letras y dígitos) conservando sus posiciones
+func tokenize(text string) []token {
+	tokens := []token{}
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, token{term: Normalize(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: Normalize(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// highlight escapa el texto y marca las palabras coincidentes con <mark>. Con
+// window > 0 devuelve solo un fragmento de esa cantidad de palabras alrededor de
+// la primera coincidencia.
+func highlight(text string, matched map[string]bool, window int) string {
+	tokens := tokenize(text)
+
+	from, to := 0, len(tokens)
+	if window > 0 && len(tokens) > window {
+		first := 0
+		for i, tok := range tokens {
+			if matched[tok.term] {
+				first = i
+				break
+			}
+		}
+		from = maxInt(first-snippetBefore, 0)
+		to = minInt(from+window, len(tokens))
+		from = maxInt(to-window, 0)
+	}
+
+	var b strings.Builder
+	pos := 0
+	if from > 0 {
+		b.WriteString("…")
+		pos = tokens[from].start
+	}
+	for _, tok := range tokens[from:to] {
+		b.WriteString(html.EscapeString(text[pos:tok.start]))
+		word := html.EscapeString(text[tok.start:tok.end])
+		if matched[tok.term] {
+			b.WriteString("<mark>" + word + "</mark>")
+		} else {
+			b.WriteString(word)
+		}
+		pos = tok.end
+	}
+	if to < len(tokens) {
+		b.WriteString("…")
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func hasTags(docTags, required []string) bool {
+	for _, want := range required {
+		found := false
+		for _, tag := range docTags {
+			if strings.EqualFold(strings.TrimSpace(want), tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
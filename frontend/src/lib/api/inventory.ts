@@ -19,7 +19,9 @@ export interface InventoryItem {
   type: string;
   description?: string;
   quantity: number;
-  value: number;
+  priceCp: number; // Precio por unidad en pc
+  denomination?: string; // Moneda en que se cotiza (cp, sp, ep, gp, pp)
+  value: number; // Precio por unidad en po (derivado de priceCp)
   weaponData?: WeaponData;
   armorData?: ArmorData;
   open5eSlug?: string;
@@ -66,14 +68,23 @@ export interface ArmorData {
 export interface Currency {
   copper: number;
   silver: number;
+  electrum: number;
   gold: number;
   platinum: number;
 }
 
+export interface Worth {
+  totalCp: number;
+  breakdown: Currency;
+}
+
 export interface InventoryResponse {
   items: InventoryItem[];
   currency: Currency;
-  totalValue: number;
+  totalValue: number; // En po, items + monedas
+  itemsWorth: Worth;
+  coinsWorth: Worth;
+  netWorth: Worth;
 }
 
 export interface Open5eItem {
@@ -194,7 +205,7 @@ export const inventoryApi = {
     value?: number;
   }) =>
     fetchWithAuth<InventoryItem>(`/items/${itemId}`, {
-      method: 'PUT',
+      method: 'PATCH',
       body: JSON.stringify(data),
     }),
 